@@ -0,0 +1,159 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+	"github.com/kubevela/pkg/util/k8s"
+)
+
+const (
+	// ControllerApplyUnprunedFieldsKey metrics key for counting the fields
+	// released by server-side apply but not pruned from the live object
+	ControllerApplyUnprunedFieldsKey = "controller_apply_unpruned_fields_total"
+)
+
+var (
+	// controllerApplyUnprunedFields the unpruned fields metrics
+	controllerApplyUnprunedFields = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerApplyUnprunedFieldsKey,
+			Help:      "number of fields released by server-side apply but not pruned",
+		}, []string{"kind"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerApplyUnprunedFields)
+}
+
+// createOrPatch creates the object if not exists, otherwise patches the
+// existing one with the fields differing from the desired object. It returns
+// whether the object is created or patched.
+func createOrPatch(ctx context.Context, c client.Client, obj client.Object) (bool, error) {
+	existing := obj.DeepCopyObject().(client.Object)
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), existing); err != nil {
+		if !kerrors.IsNotFound(err) {
+			return false, err
+		}
+		return true, c.Create(ctx, obj)
+	}
+	patch, err := BuildMergePatch(existing, obj)
+	if err != nil {
+		return false, err
+	}
+	if data, _ := patch.Data(obj); string(data) == "{}" {
+		return false, nil
+	}
+	return true, c.Patch(ctx, obj, patch)
+}
+
+// ApplyWithPrune server-side applies the object with the field manager, and
+// verifies that the fields previously managed by the field manager but absent
+// in the object are pruned from the live object, unless they are managed by
+// other managers. Fields not pruned are logged and counted in metrics. Only
+// fields addressed by names are verified, list items are skipped. The object
+// is updated with the live object returned by the apply.
+func ApplyWithPrune(ctx context.Context, c client.Client, obj client.Object, fieldManager string) error {
+	var prev *fieldpath.Set
+	live := obj.DeepCopyObject().(client.Object)
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), live); err == nil {
+		prev, _ = getManagedFieldSet(live, func(manager string) bool { return manager == fieldManager })
+	} else if !kerrors.IsNotFound(err) {
+		return err
+	}
+	obj.SetManagedFields(nil)
+	obj.SetResourceVersion("")
+	if err := c.Patch(ctx, obj, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership); err != nil {
+		return err
+	}
+	if prev != nil {
+		verifyPruned(obj, fieldManager, prev)
+	}
+	return nil
+}
+
+// verifyPruned checks the fields released by the field manager are pruned
+// from the live object
+func verifyPruned(obj client.Object, fieldManager string, prev *fieldpath.Set) {
+	current, err := getManagedFieldSet(obj, func(manager string) bool { return manager == fieldManager })
+	if err != nil {
+		return
+	}
+	others, err := getManagedFieldSet(obj, func(manager string) bool { return manager != fieldManager })
+	if err != nil {
+		return
+	}
+	content, err := toUnstructuredContent(obj)
+	if err != nil {
+		return
+	}
+	kind := k8s.GetKindForObject(obj, true)
+	prev.Difference(current).Leaves().Iterate(func(path fieldpath.Path) {
+		if others.Has(path) {
+			return
+		}
+		var fields []string
+		for _, pe := range path {
+			if pe.FieldName == nil {
+				return
+			}
+			fields = append(fields, *pe.FieldName)
+		}
+		if _, found, _ := unstructured.NestedFieldNoCopy(content, fields...); found {
+			controllerApplyUnprunedFields.WithLabelValues(kind).Inc()
+			klog.InfoS("released field not pruned by server-side apply", "kind", kind,
+				"object", klog.KObj(obj), "field", path.String(), "manager", fieldManager)
+		}
+	})
+}
+
+// getManagedFieldSet returns the union of the fields managed by the matched
+// managers
+func getManagedFieldSet(obj client.Object, match func(manager string) bool) (*fieldpath.Set, error) {
+	set := &fieldpath.Set{}
+	for _, entry := range obj.GetManagedFields() {
+		if !match(entry.Manager) || entry.FieldsV1 == nil {
+			continue
+		}
+		fields := &fieldpath.Set{}
+		if err := fields.FromJSON(bytes.NewReader(entry.FieldsV1.Raw)); err != nil {
+			return nil, err
+		}
+		set = set.Union(fields)
+	}
+	return set, nil
+}
+
+func toUnstructuredContent(obj client.Object) (map[string]interface{}, error) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u.Object, nil
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/prometheus/client_golang/prometheus"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+	"github.com/kubevela/pkg/util/k8s"
+)
+
+const (
+	// ControllerApplyIfChangedKey metrics key for counting the conditional
+	// server-side applies, by whether the apply is skipped
+	ControllerApplyIfChangedKey = "controller_apply_if_changed_total"
+)
+
+var (
+	// controllerApplyIfChanged the conditional apply metrics
+	controllerApplyIfChanged = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerApplyIfChangedKey,
+			Help:      "number of conditional server-side applies, by result of noop or applied",
+		}, []string{"kind", "result"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerApplyIfChanged)
+}
+
+// ApplyResult the result of ApplyIfChanged
+type ApplyResult string
+
+const (
+	// ApplyResultNoop the apply is skipped as nothing would change
+	ApplyResultNoop ApplyResult = "noop"
+	// ApplyResultApplied the object is server-side applied
+	ApplyResultApplied ApplyResult = "applied"
+)
+
+// ApplyIfChanged server-side applies the object with the field manager, unless
+// the apply would change nothing. The apply is skipped if every field of the
+// desired object equals the live one and is already managed by the field
+// manager, and every field managed by the field manager in the live object is
+// still in the desired object, i.e. no field would be changed, taken over or
+// pruned. Lists are compared item by item. The status and
+// the volatile metadata are ignored. If the apply is skipped, the object is
+// updated with the live object, as if it has been applied.
+func ApplyIfChanged(ctx context.Context, c client.Client, obj client.Object, fieldManager string) (result ApplyResult, err error) {
+	kind := k8s.GetKindForObject(obj, true)
+	defer func() {
+		if err == nil {
+			controllerApplyIfChanged.WithLabelValues(kind, string(result)).Inc()
+		}
+	}()
+	live := obj.DeepCopyObject().(client.Object)
+	err = c.Get(ctx, client.ObjectKeyFromObject(obj), live)
+	if err == nil {
+		var unchanged bool
+		if unchanged, err = isApplyNoop(live, obj, fieldManager); err != nil {
+			return "", err
+		}
+		if unchanged {
+			reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(live).Elem())
+			return ApplyResultNoop, nil
+		}
+	} else if !kerrors.IsNotFound(err) {
+		return "", err
+	}
+	obj.SetManagedFields(nil)
+	obj.SetResourceVersion("")
+	if err = c.Patch(ctx, obj, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership); err != nil {
+		return "", err
+	}
+	return ApplyResultApplied, nil
+}
+
+// isApplyNoop check if applying the desired object would change nothing in
+// the live object
+func isApplyNoop(live client.Object, desired client.Object, fieldManager string) (bool, error) {
+	managed, err := getManagedFieldSet(live, func(manager string) bool { return manager == fieldManager })
+	if err != nil {
+		return false, err
+	}
+	liveContent, err := toUnstructuredContent(live)
+	if err != nil {
+		return false, err
+	}
+	desiredContent, err := toUnstructuredContent(desired)
+	if err != nil {
+		return false, err
+	}
+	desiredContent = stripApplyIgnoredFields(desiredContent)
+	if !isSubset(desiredContent, liveContent) {
+		return false, nil
+	}
+	pruned := false
+	leaves := managed.Leaves()
+	leaves.Iterate(func(path fieldpath.Path) {
+		if _, found := lookupFieldPath(desiredContent, path); !found {
+			pruned = true
+		}
+	})
+	if pruned {
+		return false, nil
+	}
+	if metadata, ok := desiredContent["metadata"].(map[string]interface{}); ok {
+		// name and namespace are not tracked in managed fields
+		desiredContent["metadata"] = copyMapWithout(metadata, "name", "namespace")
+	}
+	return isManaged(desiredContent, fieldpath.Path{}, leaves), nil
+}
+
+// isManaged check if all the leaf fields in the content are managed. Lists are
+// seen as managed if any item in it is managed.
+func isManaged(content interface{}, path fieldpath.Path, leaves *fieldpath.Set) bool {
+	switch v := content.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		for k, sub := range v {
+			name := k
+			if !isManaged(sub, append(path.Copy(), fieldpath.PathElement{FieldName: &name}), leaves) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		if len(v) == 0 {
+			return leaves.Has(path)
+		}
+		managed := false
+		leaves.Iterate(func(p fieldpath.Path) {
+			if len(p) > len(path) && p[:len(path)].Equals(path) {
+				managed = true
+			}
+		})
+		return managed
+	default:
+		return leaves.Has(path)
+	}
+}
+
+// copyMapWithout returns a shallow copy of the map without the keys
+func copyMapWithout(m map[string]interface{}, keys ...string) map[string]interface{} {
+	copied := map[string]interface{}{}
+	for k, v := range m {
+		copied[k] = v
+	}
+	for _, k := range keys {
+		delete(copied, k)
+	}
+	return copied
+}
+
+// stripApplyIgnoredFields returns a shallow copy of the content without the
+// type meta, the status and the volatile metadata
+func stripApplyIgnoredFields(content map[string]interface{}) map[string]interface{} {
+	stripped := copyMapWithout(content, "apiVersion", "kind", "status")
+	if metadata, ok := stripped["metadata"].(map[string]interface{}); ok {
+		stripped["metadata"] = copyMapWithout(metadata, volatileMetadataFields...)
+	}
+	return stripped
+}
+
+// isSubset check if all the fields in desired equal the ones in live. Lists
+// must have the same length, with each item being the subset of the live one.
+// Null and empty maps in desired match the absent fields in live, as they are
+// usually zero values of typed objects.
+func isSubset(desired interface{}, live interface{}) bool {
+	switch d := desired.(type) {
+	case nil:
+		return live == nil
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return live == nil && len(d) == 0
+		}
+		for k, v := range d {
+			lv, found := l[k]
+			if !found {
+				if m, isMap := v.(map[string]interface{}); v == nil || (isMap && len(m) == 0) {
+					continue
+				}
+				return false
+			}
+			if !isSubset(v, lv) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok || len(l) != len(d) {
+			return false
+		}
+		for i := range d {
+			if !isSubset(d[i], l[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return equalJSON(desired, live)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestApplyIfChanged(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	meta := metav1.ObjectMeta{Namespace: "default", Name: "example"}
+	c := &applyClient{Client: fake.NewClientBuilder().Build(), prune: true}
+	noops := testutil.ToFloat64(controllerApplyIfChanged.WithLabelValues("ConfigMap", "noop"))
+
+	result, err := ApplyIfChanged(ctx, c, &corev1.ConfigMap{ObjectMeta: meta, Data: map[string]string{"a": "1", "b": "2"}}, "vela")
+	r.NoError(err)
+	r.Equal(ApplyResultApplied, result)
+	r.Equal(1, c.applies)
+
+	// unchanged object is not applied
+	cm := &corev1.ConfigMap{ObjectMeta: meta, Data: map[string]string{"a": "1", "b": "2"}}
+	result, err = ApplyIfChanged(ctx, c, cm, "vela")
+	r.NoError(err)
+	r.Equal(ApplyResultNoop, result)
+	r.Equal(1, c.applies)
+	r.NotEmpty(cm.ResourceVersion)
+	r.Equal(noops+1, testutil.ToFloat64(controllerApplyIfChanged.WithLabelValues("ConfigMap", "noop")))
+
+	// changed or pruned fields are applied
+	result, err = ApplyIfChanged(ctx, c, &corev1.ConfigMap{ObjectMeta: meta, Data: map[string]string{"a": "changed", "b": "2"}}, "vela")
+	r.NoError(err)
+	r.Equal(ApplyResultApplied, result)
+	result, err = ApplyIfChanged(ctx, c, &corev1.ConfigMap{ObjectMeta: meta, Data: map[string]string{"a": "changed"}}, "vela")
+	r.NoError(err)
+	r.Equal(ApplyResultApplied, result)
+	// fields managed by others do not count
+	result, err = ApplyIfChanged(ctx, c, &corev1.ConfigMap{ObjectMeta: meta, Data: map[string]string{"a": "changed"}}, "other")
+	r.NoError(err)
+	r.Equal(ApplyResultApplied, result)
+	result, err = ApplyIfChanged(ctx, c, &corev1.ConfigMap{ObjectMeta: meta, Data: map[string]string{"a": "changed"}}, "vela")
+	r.NoError(err)
+	r.Equal(ApplyResultApplied, result)
+	r.Equal(5, c.applies)
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// applyClient simulates server-side apply for ConfigMaps, as the fake client
+// does not support apply patches
+type applyClient struct {
+	client.Client
+	prune   bool
+	applies int
+}
+
+func (c *applyClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		return c.Client.Patch(ctx, obj, patch, opts...)
+	}
+	c.applies++
+	o := &client.PatchOptions{}
+	o.ApplyOptions(opts)
+	desired := obj.(*corev1.ConfigMap)
+	fields := map[string]interface{}{}
+	for k := range desired.Data {
+		fields["f:"+k] = map[string]interface{}{}
+	}
+	raw, err := json.Marshal(map[string]interface{}{"f:data": fields})
+	if err != nil {
+		return err
+	}
+	live := &corev1.ConfigMap{}
+	if err = c.Client.Get(ctx, client.ObjectKeyFromObject(obj), live); err != nil {
+		if !kerrors.IsNotFound(err) {
+			return err
+		}
+		live = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: obj.GetNamespace(), Name: obj.GetName()}}
+	}
+	data := map[string]string{}
+	if !c.prune {
+		for k, v := range live.Data {
+			data[k] = v
+		}
+	}
+	for k, v := range desired.Data {
+		data[k] = v
+	}
+	live.Data = data
+	live.ManagedFields = []metav1.ManagedFieldsEntry{{
+		Manager:    o.FieldManager,
+		Operation:  metav1.ManagedFieldsOperationApply,
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: raw},
+	}}
+	if live.ResourceVersion == "" {
+		err = c.Client.Create(ctx, live)
+	} else {
+		err = c.Client.Update(ctx, live)
+	}
+	if err != nil {
+		return err
+	}
+	live.DeepCopyInto(desired)
+	return nil
+}
+
+func TestApplyWithPrune(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	meta := metav1.ObjectMeta{Namespace: "default", Name: "example"}
+	c := &applyClient{Client: fake.NewClientBuilder().Build(), prune: true}
+	unpruned := testutil.ToFloat64(controllerApplyUnprunedFields.WithLabelValues("ConfigMap"))
+	r.NoError(ApplyWithPrune(ctx, c, &corev1.ConfigMap{ObjectMeta: meta, Data: map[string]string{"a": "1", "b": "2"}}, "vela"))
+	r.NoError(ApplyWithPrune(ctx, c, &corev1.ConfigMap{ObjectMeta: meta, Data: map[string]string{"a": "1"}}, "vela"))
+	live := &corev1.ConfigMap{}
+	r.NoError(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "example"}, live))
+	r.Equal(map[string]string{"a": "1"}, live.Data)
+	r.Equal(unpruned, testutil.ToFloat64(controllerApplyUnprunedFields.WithLabelValues("ConfigMap")))
+
+	// released fields left in the live object are counted
+	c.prune = false
+	r.NoError(ApplyWithPrune(ctx, c, &corev1.ConfigMap{ObjectMeta: meta, Data: map[string]string{"a": "1", "c": "3"}}, "vela"))
+	r.NoError(ApplyWithPrune(ctx, c, &corev1.ConfigMap{ObjectMeta: meta, Data: map[string]string{"a": "1"}}, "vela"))
+	r.Equal(unpruned+1, testutil.ToFloat64(controllerApplyUnprunedFields.WithLabelValues("ConfigMap")))
+}
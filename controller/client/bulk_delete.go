@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+)
+
+const (
+	// ControllerBulkDeleteKey metrics key for counting the bulk deletions
+	ControllerBulkDeleteKey = "controller_bulk_delete_total"
+	// ControllerBulkDeletedObjectsKey metrics key for counting the objects
+	// deleted by bulk deletions
+	ControllerBulkDeletedObjectsKey = "controller_bulk_deleted_objects_total"
+)
+
+var (
+	// controllerBulkDelete the bulk deletion metrics
+	controllerBulkDelete = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerBulkDeleteKey,
+			Help:      "number of bulk deletions, by result of success, error or aborted",
+		}, []string{"kind", "result"})
+
+	// controllerBulkDeletedObjects the objects deleted by bulk deletions metrics
+	controllerBulkDeletedObjects = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerBulkDeletedObjectsKey,
+			Help:      "number of objects deleted by bulk deletions",
+		}, []string{"kind"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerBulkDelete, controllerBulkDeletedObjects)
+}
+
+// listBulkDeleteTargets lists the objects to be deleted and computes the
+// confirmation token from their UIDs
+func listBulkDeleteTargets(ctx context.Context, c client.Client, gvk schema.GroupVersionKind, namespace string, selector labels.Selector) ([]unstructured.Unstructured, string, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err := c.List(ctx, list, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, "", err
+	}
+	uids := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		uids = append(uids, string(item.GetUID()))
+	}
+	sort.Strings(uids)
+	hash := sha256.New()
+	for _, uid := range uids {
+		hash.Write([]byte(uid + "\n"))
+	}
+	return list.Items, hex.EncodeToString(hash.Sum(nil))[:16], nil
+}
+
+// BulkDeleteConfirmToken lists the objects of the kind matching the selector
+// in the namespace, and returns the number of them and the token for
+// confirming their deletion with BulkDelete. An empty namespace means all
+// namespaces. The token is derived from the UIDs of the listed objects, so it
+// is invalidated once the matched objects change.
+func BulkDeleteConfirmToken(ctx context.Context, c client.Client, gvk schema.GroupVersionKind, namespace string, selector labels.Selector) (token string, count int, err error) {
+	items, token, err := listBulkDeleteTargets(ctx, c, gvk, namespace, selector)
+	return token, len(items), err
+}
+
+// BulkDelete deletes the objects of the kind matching the selector in the
+// namespace. It forces a two-step confirmation: the objects are listed first,
+// and only deleted if their number equals expectedCount and the confirmToken
+// equals the one returned by BulkDeleteConfirmToken for the same objects.
+// Otherwise, nothing is deleted and the mismatch is returned as error. Each
+// deletion is preconditioned on the UID of the listed object, so that objects
+// recreated with the same name after listing are not deleted. It returns the
+// number of deleted objects and the errors of failed deletions.
+func BulkDelete(ctx context.Context, c client.Client, gvk schema.GroupVersionKind, namespace string, selector labels.Selector, confirmToken string, expectedCount int) (deleted int, err error) {
+	result := "success"
+	defer func() {
+		controllerBulkDelete.WithLabelValues(gvk.Kind, result).Inc()
+		controllerBulkDeletedObjects.WithLabelValues(gvk.Kind).Add(float64(deleted))
+	}()
+	items, token, err := listBulkDeleteTargets(ctx, c, gvk, namespace, selector)
+	if err != nil {
+		result = "error"
+		return 0, err
+	}
+	if len(items) != expectedCount {
+		result = "aborted"
+		return 0, fmt.Errorf("bulk deletion of %s aborted: expected %d objects but found %d", gvk.Kind, expectedCount, len(items))
+	}
+	if confirmToken != token {
+		result = "aborted"
+		return 0, fmt.Errorf("bulk deletion of %s aborted: confirm token %q does not match the matched objects", gvk.Kind, confirmToken)
+	}
+	var errs []error
+	for i := range items {
+		uid := items[i].GetUID()
+		if err = client.IgnoreNotFound(c.Delete(ctx, &items[i], client.Preconditions{UID: &uid})); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete %s %s: %w", gvk.Kind, client.ObjectKeyFromObject(&items[i]), err))
+			continue
+		}
+		deleted++
+	}
+	if err = kerrors.NewAggregate(errs); err != nil {
+		result = "error"
+	}
+	return deleted, err
+}
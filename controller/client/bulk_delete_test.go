@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type deletePreconditionsClient struct {
+	client.Client
+	uids []types.UID
+}
+
+func (c *deletePreconditionsClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if preconditions := (&client.DeleteOptions{}).ApplyOptions(opts).Preconditions; preconditions != nil && preconditions.UID != nil {
+		c.uids = append(c.uids, *preconditions.UID)
+	}
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func TestBulkDelete(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	c := &deletePreconditionsClient{Client: fake.NewClientBuilder().WithObjects(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a", UID: "a", Labels: map[string]string{"app": "example"}}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "b", UID: "b", Labels: map[string]string{"app": "example"}}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "c", UID: "c", Labels: map[string]string{"app": "other"}}},
+	).Build()}
+	gvk := corev1.SchemeGroupVersion.WithKind("ConfigMap")
+	selector := labels.SelectorFromSet(labels.Set{"app": "example"})
+	aborted := testutil.ToFloat64(controllerBulkDelete.WithLabelValues("ConfigMap", "aborted"))
+	succeeded := testutil.ToFloat64(controllerBulkDelete.WithLabelValues("ConfigMap", "success"))
+	deletedObjects := testutil.ToFloat64(controllerBulkDeletedObjects.WithLabelValues("ConfigMap"))
+
+	token, count, err := BulkDeleteConfirmToken(ctx, c, gvk, "default", selector)
+	r.NoError(err)
+	r.Equal(2, count)
+
+	deleted, err := BulkDelete(ctx, c, gvk, "default", selector, token, 3)
+	r.ErrorContains(err, "expected 3 objects but found 2")
+	r.Equal(0, deleted)
+	deleted, err = BulkDelete(ctx, c, gvk, "default", selector, "invalid", 2)
+	r.ErrorContains(err, "does not match")
+	r.Equal(0, deleted)
+	r.Equal(aborted+2, testutil.ToFloat64(controllerBulkDelete.WithLabelValues("ConfigMap", "aborted")))
+	cms := &corev1.ConfigMapList{}
+	r.NoError(c.List(ctx, cms))
+	r.Len(cms.Items, 3)
+
+	// token is invalidated once the matched objects change
+	r.NoError(c.Create(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "d", UID: "d", Labels: map[string]string{"app": "example"}}}))
+	_, err = BulkDelete(ctx, c, gvk, "default", selector, token, 3)
+	r.ErrorContains(err, "does not match")
+	token, count, err = BulkDeleteConfirmToken(ctx, c, gvk, "default", selector)
+	r.NoError(err)
+
+	deleted, err = BulkDelete(ctx, c, gvk, "default", selector, token, count)
+	r.NoError(err)
+	r.Equal(3, deleted)
+	r.ElementsMatch([]types.UID{"a", "b", "d"}, c.uids)
+	r.NoError(c.List(ctx, cms))
+	r.Len(cms.Items, 1)
+	r.Equal("c", cms.Items[0].Name)
+	r.Equal(succeeded+1, testutil.ToFloat64(controllerBulkDelete.WithLabelValues("ConfigMap", "success")))
+	r.Equal(deletedObjects+3, testutil.ToFloat64(controllerBulkDeletedObjects.WithLabelValues("ConfigMap")))
+}
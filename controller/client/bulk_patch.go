@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+	"github.com/kubevela/pkg/util/slices"
+)
+
+const (
+	// ControllerBulkPatchKey metrics key for counting the objects patched by
+	// bulk patching
+	ControllerBulkPatchKey = "controller_bulk_patch_total"
+
+	// bulkPatchResultSuccess the result label for succeeded patches
+	bulkPatchResultSuccess = "success"
+	// bulkPatchResultError the result label for failed patches
+	bulkPatchResultError = "error"
+)
+
+var (
+	// controllerBulkPatch the bulk patching metrics
+	controllerBulkPatch = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerBulkPatchKey,
+			Help:      "number of objects patched by bulk patching",
+		}, []string{"kind", "result"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerBulkPatch)
+}
+
+// PatchLabelsAcross adds the labels to all the objects of the kind in the
+// namespace. An empty namespace means all namespaces. Objects already having
+// the labels are skipped, others are patched concurrently with minimal merge
+// patches. If parallelism is not positive, slices.DefaultParallelism is used.
+// It returns the number of patched objects and the errors encountered.
+func PatchLabelsAcross(ctx context.Context, c client.Client, gvk schema.GroupVersionKind, namespace string, add map[string]string, parallelism int) (patched int, errs []error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		return 0, []error{err}
+	}
+	var objs []*unstructured.Unstructured
+	for i := range list.Items {
+		if !hasLabels(&list.Items[i], add) {
+			objs = append(objs, &list.Items[i])
+		}
+	}
+	if parallelism <= 0 {
+		parallelism = slices.DefaultParallelism
+	}
+	results := slices.ParMap(objs, func(obj *unstructured.Unstructured) error {
+		desired := obj.DeepCopy()
+		labels := desired.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		for k, v := range add {
+			labels[k] = v
+		}
+		desired.SetLabels(labels)
+		patch, err := BuildMergePatch(obj, desired)
+		if err == nil {
+			err = c.Patch(ctx, obj, patch)
+		}
+		if err != nil {
+			controllerBulkPatch.WithLabelValues(gvk.Kind, bulkPatchResultError).Inc()
+			return fmt.Errorf("failed to patch labels for %s %s: %w", gvk.Kind, client.ObjectKeyFromObject(obj), err)
+		}
+		controllerBulkPatch.WithLabelValues(gvk.Kind, bulkPatchResultSuccess).Inc()
+		return nil
+	}, slices.Parallelism(parallelism))
+	for _, err := range results {
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			patched++
+		}
+	}
+	return patched, errs
+}
+
+func hasLabels(obj client.Object, labels map[string]string) bool {
+	current := obj.GetLabels()
+	for k, v := range labels {
+		if val, found := current[k]; !found || val != v {
+			return false
+		}
+	}
+	return true
+}
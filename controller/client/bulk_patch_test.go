@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type patchRecordClient struct {
+	client.Client
+	mu      sync.Mutex
+	patched []string
+}
+
+func (c *patchRecordClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	c.mu.Lock()
+	c.patched = append(c.patched, obj.GetName())
+	c.mu.Unlock()
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func TestPatchLabelsAcross(t *testing.T) {
+	r := require.New(t)
+	c := &patchRecordClient{Client: fake.NewClientBuilder().WithObjects(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "b", Labels: map[string]string{"app": "b"}}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "c", Labels: map[string]string{"migrated": "true"}}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "d", Labels: map[string]string{"migrated": "false"}}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "e"}},
+	).Build()}
+	ctx := context.Background()
+	succeeded := testutil.ToFloat64(controllerBulkPatch.WithLabelValues("ConfigMap", bulkPatchResultSuccess))
+	patched, errs := PatchLabelsAcross(ctx, c, corev1.SchemeGroupVersion.WithKind("ConfigMap"), "default", map[string]string{"migrated": "true"}, 2)
+	r.Empty(errs)
+	r.Equal(3, patched)
+	r.ElementsMatch([]string{"a", "b", "d"}, c.patched)
+	r.Equal(succeeded+3, testutil.ToFloat64(controllerBulkPatch.WithLabelValues("ConfigMap", bulkPatchResultSuccess)))
+
+	cms := &corev1.ConfigMapList{}
+	r.NoError(c.List(ctx, cms))
+	for _, cm := range cms.Items {
+		if cm.Namespace == "default" {
+			r.Equal("true", cm.Labels["migrated"])
+		} else {
+			r.NotContains(cm.Labels, "migrated")
+		}
+	}
+	b := &corev1.ConfigMap{}
+	r.NoError(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "b"}, b))
+	r.Equal("b", b.Labels["app"])
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ReconcileCheckpointAnnotation the annotation storing the reconcile
+	// checkpoint of the object
+	ReconcileCheckpointAnnotation = "controller.kubevela.io/reconcile-checkpoint"
+
+	// checkpointHashLength the length of the object hash kept in checkpoints
+	checkpointHashLength = 16
+)
+
+var (
+	// MaxCheckpointPhaseLength the max length of the phase recorded in the
+	// reconcile checkpoint, which bounds the size of the annotation
+	MaxCheckpointPhaseLength = 64
+)
+
+// ReconcileCheckpoint the progress of the reconcile persisted on the object,
+// so that a restarted controller can resume from it
+type ReconcileCheckpoint struct {
+	// Phase the reconcile phase reached
+	Phase string `json:"phase"`
+	// Time when the phase is reached
+	Time metav1.Time `json:"time"`
+	// Hash the hash of the object when the phase is reached
+	Hash string `json:"hash"`
+}
+
+// Matches check if the object is unchanged since the checkpoint is recorded,
+// i.e. the progress of the checkpoint still applies to the object
+func (in *ReconcileCheckpoint) Matches(obj client.Object) bool {
+	hash, err := hashCheckpointObject(obj)
+	return err == nil && hash == in.Hash
+}
+
+// hashCheckpointObject hashes the object like PlanReconcile, excluding the
+// checkpoint annotation itself
+func hashCheckpointObject(obj client.Object) (string, error) {
+	if _, found := obj.GetAnnotations()[ReconcileCheckpointAnnotation]; found {
+		obj = obj.DeepCopyObject().(client.Object)
+		annotations := obj.GetAnnotations()
+		delete(annotations, ReconcileCheckpointAnnotation)
+		obj.SetAnnotations(annotations)
+	}
+	hash, err := hashObjectSpec(obj)
+	if err != nil {
+		return "", err
+	}
+	return hash[:checkpointHashLength], nil
+}
+
+// RecordReconcileCheckpoint records the phase reached by the reconcile, with
+// the current time and the hash of the object, in the annotation of the
+// object. Only the annotation is patched. The phase is limited to
+// MaxCheckpointPhaseLength characters to bound the annotation size.
+func RecordReconcileCheckpoint(ctx context.Context, c client.Client, obj client.Object, phase string) error {
+	if len(phase) > MaxCheckpointPhaseLength {
+		return fmt.Errorf("checkpoint phase exceeds %d characters: %s", MaxCheckpointPhaseLength, phase)
+	}
+	hash, err := hashCheckpointObject(obj)
+	if err != nil {
+		return err
+	}
+	bs, err := json.Marshal(&ReconcileCheckpoint{Phase: phase, Time: metav1.Now(), Hash: hash})
+	if err != nil {
+		return err
+	}
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[ReconcileCheckpointAnnotation] = string(bs)
+	obj.SetAnnotations(annotations)
+	return c.Patch(ctx, obj, patch)
+}
+
+// ReadCheckpoint returns the reconcile checkpoint recorded on the object, nil
+// if not recorded
+func ReadCheckpoint(obj client.Object) (*ReconcileCheckpoint, error) {
+	val, found := obj.GetAnnotations()[ReconcileCheckpointAnnotation]
+	if !found {
+		return nil, nil
+	}
+	checkpoint := &ReconcileCheckpoint{}
+	if err := json.Unmarshal([]byte(val), checkpoint); err != nil {
+		return nil, fmt.Errorf("invalid reconcile checkpoint: %w", err)
+	}
+	return checkpoint, nil
+}
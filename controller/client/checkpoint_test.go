@@ -0,0 +1,65 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileCheckpoint(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example"},
+		Data:       map[string]string{"key": "value"},
+	}
+	c := fake.NewClientBuilder().WithObjects(cm).Build()
+	r.NoError(c.Get(ctx, client.ObjectKeyFromObject(cm), cm))
+
+	checkpoint, err := ReadCheckpoint(cm)
+	r.NoError(err)
+	r.Nil(checkpoint)
+
+	r.NoError(RecordReconcileCheckpoint(ctx, c, cm, "rendered"))
+	r.NoError(RecordReconcileCheckpoint(ctx, c, cm, "applied"))
+	r.ErrorContains(RecordReconcileCheckpoint(ctx, c, cm, strings.Repeat("x", 65)), "exceeds 64 characters")
+
+	live := &corev1.ConfigMap{}
+	r.NoError(c.Get(ctx, client.ObjectKeyFromObject(cm), live))
+	checkpoint, err = ReadCheckpoint(live)
+	r.NoError(err)
+	r.Equal("applied", checkpoint.Phase)
+	r.WithinDuration(time.Now(), checkpoint.Time.Time, 5*time.Second)
+	r.Len(checkpoint.Hash, checkpointHashLength)
+	r.True(checkpoint.Matches(live))
+
+	live.Data["key"] = "changed"
+	r.False(checkpoint.Matches(live))
+
+	live.Annotations[ReconcileCheckpointAnnotation] = "invalid"
+	_, err = ReadCheckpoint(live)
+	r.Error(err)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+)
+
+const (
+	// ControllerClusterDiffObjectsKey metrics key for recording the number of
+	// objects differing between two clusters
+	ControllerClusterDiffObjectsKey = "controller_cluster_diff_objects"
+
+	// clusterDiffOnly the state label for objects only in the cluster
+	clusterDiffOnly = "only"
+	// clusterDiffDiffering the state label for objects in both the cluster
+	// and the peer but differing
+	clusterDiffDiffering = "differing"
+)
+
+var (
+	// DiffClustersPageSize the page size for listing objects when diffing
+	// clusters
+	DiffClustersPageSize int64 = 500
+)
+
+var (
+	// controllerClusterDiffObjects the cluster diff metrics
+	controllerClusterDiffObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerClusterDiffObjectsKey,
+			Help:      "number of objects differing between the cluster and the peer in the last diff, by the state of only in the cluster or differing",
+		}, []string{"cluster", "peer", "kind", "namespace", "state"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerClusterDiffObjects)
+}
+
+// DiffClusters compares the objects of the kind in the namespace between the
+// two clusters, named aCluster and bCluster. An empty namespace means all
+// namespaces. Objects are matched by namespace and name, and compared by the
+// hash of the fields compared by PlanReconcile, such as spec, data, labels and
+// annotations. It returns the sorted keys of the objects only in cluster a,
+// only in cluster b, and in both but differing. Objects are listed in pages of
+// DiffClustersPageSize. The numbers of the objects are recorded in metrics by
+// the cluster names: the objects only in one cluster under the cluster label
+// of it and the peer label of the other with the state only, and the
+// differing objects under the cluster label of a and the peer label of b with
+// the state differing.
+func DiffClusters(ctx context.Context, aCluster string, a client.Client, bCluster string, b client.Client, gvk schema.GroupVersionKind, namespace string) (onlyA, onlyB, differing []client.ObjectKey, err error) {
+	aHashes, err := listObjectHashes(ctx, a, gvk, namespace)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	bHashes, err := listObjectHashes(ctx, b, gvk, namespace)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for key, aHash := range aHashes {
+		bHash, found := bHashes[key]
+		switch {
+		case !found:
+			onlyA = append(onlyA, key)
+		case aHash != bHash:
+			differing = append(differing, key)
+		}
+	}
+	for key := range bHashes {
+		if _, found := aHashes[key]; !found {
+			onlyB = append(onlyB, key)
+		}
+	}
+	for _, keys := range [][]client.ObjectKey{onlyA, onlyB, differing} {
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	}
+	controllerClusterDiffObjects.WithLabelValues(aCluster, bCluster, gvk.Kind, namespace, clusterDiffOnly).Set(float64(len(onlyA)))
+	controllerClusterDiffObjects.WithLabelValues(bCluster, aCluster, gvk.Kind, namespace, clusterDiffOnly).Set(float64(len(onlyB)))
+	controllerClusterDiffObjects.WithLabelValues(aCluster, bCluster, gvk.Kind, namespace, clusterDiffDiffering).Set(float64(len(differing)))
+	return onlyA, onlyB, differing, nil
+}
+
+// listObjectHashes lists the objects page by page and returns their hashes
+func listObjectHashes(ctx context.Context, c client.Client, gvk schema.GroupVersionKind, namespace string) (map[client.ObjectKey]string, error) {
+	hashes := map[client.ObjectKey]string{}
+	opts := []client.ListOption{client.InNamespace(namespace), client.Limit(DiffClustersPageSize)}
+	for {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := c.List(ctx, list, opts...); err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			hash, err := hashObjectSpec(&list.Items[i])
+			if err != nil {
+				return nil, err
+			}
+			hashes[client.ObjectKeyFromObject(&list.Items[i])] = hash
+		}
+		if list.GetContinue() == "" {
+			return hashes, nil
+		}
+		opts = []client.ListOption{client.InNamespace(namespace), client.Limit(DiffClustersPageSize), client.Continue(list.GetContinue())}
+	}
+}
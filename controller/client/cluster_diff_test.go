@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// pagingClient serves the list requests in pages, as the fake client ignores
+// the limit and continue options
+type pagingClient struct {
+	client.Client
+	pages int
+}
+
+func (c *pagingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if err := c.Client.List(ctx, list, opts...); err != nil {
+		return err
+	}
+	listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+	c.pages++
+	u := list.(*unstructured.UnstructuredList)
+	begin, _ := strconv.Atoi(listOpts.Continue)
+	end := begin + int(listOpts.Limit)
+	if end < len(u.Items) {
+		u.SetContinue(strconv.Itoa(end))
+	} else {
+		end = len(u.Items)
+	}
+	u.Items = u.Items[begin:end]
+	return nil
+}
+
+func TestDiffClusters(t *testing.T) {
+	r := require.New(t)
+	defer func(size int64) { DiffClustersPageSize = size }(DiffClustersPageSize)
+	DiffClustersPageSize = 2
+	ctx := context.Background()
+	a := &pagingClient{Client: fake.NewClientBuilder().WithObjects(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "same", UID: "a-1"}, Data: map[string]string{"key": "v"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "changed", UID: "a-2"}, Data: map[string]string{"key": "v1"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "only-a", UID: "a-3"}, Data: map[string]string{"key": "v"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "only-a-2", UID: "a-4"}, Data: map[string]string{"key": "v"}},
+	).Build()}
+	b := &pagingClient{Client: fake.NewClientBuilder().WithObjects(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "same", UID: "b-1"}, Data: map[string]string{"key": "v"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "changed", UID: "b-2"}, Data: map[string]string{"key": "v2"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "only-b", UID: "b-3"}, Data: map[string]string{"key": "v"}},
+	).Build()}
+
+	onlyA, onlyB, differing, err := DiffClusters(ctx, "cluster-a", a, "cluster-b", b, corev1.SchemeGroupVersion.WithKind("ConfigMap"), "default")
+	r.NoError(err)
+	r.Equal([]client.ObjectKey{{Namespace: "default", Name: "only-a"}, {Namespace: "default", Name: "only-a-2"}}, onlyA)
+	r.Equal([]client.ObjectKey{{Namespace: "default", Name: "only-b"}}, onlyB)
+	r.Equal([]client.ObjectKey{{Namespace: "default", Name: "changed"}}, differing)
+	r.Equal(2, a.pages)
+	r.Equal(2, b.pages)
+	r.Equal(2.0, testutil.ToFloat64(controllerClusterDiffObjects.WithLabelValues("cluster-a", "cluster-b", "ConfigMap", "default", "only")))
+	r.Equal(1.0, testutil.ToFloat64(controllerClusterDiffObjects.WithLabelValues("cluster-b", "cluster-a", "ConfigMap", "default", "only")))
+	r.Equal(1.0, testutil.ToFloat64(controllerClusterDiffObjects.WithLabelValues("cluster-a", "cluster-b", "ConfigMap", "default", "differing")))
+
+	// diffing another pair of clusters does not overwrite the series
+	_, _, _, err = DiffClusters(ctx, "cluster-a", a, "cluster-c", a, corev1.SchemeGroupVersion.WithKind("ConfigMap"), "default")
+	r.NoError(err)
+	r.Equal(0.0, testutil.ToFloat64(controllerClusterDiffObjects.WithLabelValues("cluster-a", "cluster-c", "ConfigMap", "default", "only")))
+	r.Equal(2.0, testutil.ToFloat64(controllerClusterDiffObjects.WithLabelValues("cluster-a", "cluster-b", "ConfigMap", "default", "only")))
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import "context"
+
+type contextKey int
+
+const (
+	// pinnedResourceVersionKey is the context key for requests pinned to
+	// a specific resourceVersion
+	pinnedResourceVersionKey contextKey = iota
+	// reconcileCacheContextKey is the context key for the reconcile cache
+	reconcileCacheContextKey
+	// defaultDeletePolicyContextKey is the context key for the default
+	// propagation policy of deletes
+	defaultDeletePolicyContextKey
+	// metricLabelOverrideContextKey is the context key for the overrides of
+	// client metrics labels
+	metricLabelOverrideContextKey
+)
+
+// withPinnedResourceVersion marks the requests in context as pinned to the
+// given resourceVersion
+func withPinnedResourceVersion(ctx context.Context, rv string) context.Context {
+	return context.WithValue(ctx, pinnedResourceVersionKey, rv)
+}
+
+// pinnedResourceVersionFrom extract the pinned resourceVersion from context
+func pinnedResourceVersionFrom(ctx context.Context) (string, bool) {
+	rv, ok := ctx.Value(pinnedResourceVersionKey).(string)
+	return rv, ok
+}
@@ -43,7 +43,7 @@ func DefaultNewControllerClient(cache cache.Cache, config *rest.Config, options
 	rawClient = WrapDefaultTimeoutClient(rawClient)
 
 	mClient := &monitorClient{rawClient}
-	mCache := &monitorCache{cache}
+	mCache := &monitorCache{Cache: cache, scheme: mClient.Scheme(), mapper: mClient.RESTMapper()}
 
 	uncachedStructuredGVKs := map[schema.GroupVersionKind]struct{}{}
 	for _, obj := range uncachedObjects {
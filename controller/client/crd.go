@@ -0,0 +1,61 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CheckRequiredCRDs checks if all the kinds are known to the RESTMapper of the
+// client, which can be used as the readiness gate at startup. If any kind is
+// missing, the RESTMapper is reset to refresh the discovery and checked again.
+// The returned error lists all the missing kinds.
+func CheckRequiredCRDs(_ context.Context, c client.Client, gvks []schema.GroupVersionKind) error {
+	mapper := c.RESTMapper()
+	missing := findMissingKinds(mapper, gvks)
+	if len(missing) == 0 {
+		return nil
+	}
+	if resettable, ok := mapper.(meta.ResettableRESTMapper); ok {
+		resettable.Reset()
+		missing = findMissingKinds(mapper, missing)
+	}
+	var errs []error
+	for _, gvk := range missing {
+		errs = append(errs, fmt.Errorf("kind %s of %s is not installed", gvk.Kind, gvk.GroupVersion()))
+	}
+	if err := kerrors.NewAggregate(errs); err != nil {
+		return fmt.Errorf("required CRDs missing: %w", err)
+	}
+	return nil
+}
+
+func findMissingKinds(mapper meta.RESTMapper, gvks []schema.GroupVersionKind) []schema.GroupVersionKind {
+	var missing []schema.GroupVersionKind
+	for _, gvk := range gvks {
+		if _, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+			missing = append(missing, gvk)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	velaclient "github.com/kubevela/pkg/controller/client"
+)
+
+type resettableMapper struct {
+	*meta.DefaultRESTMapper
+	onReset func()
+}
+
+func (m *resettableMapper) Reset() { m.onReset() }
+
+func TestCheckRequiredCRDs(t *testing.T) {
+	r := require.New(t)
+	app := schema.GroupVersionKind{Group: "core.oam.dev", Version: "v1beta1", Kind: "Application"}
+	policy := schema.GroupVersionKind{Group: "core.oam.dev", Version: "v1alpha1", Kind: "Policy"}
+	workflow := schema.GroupVersionKind{Group: "core.oam.dev", Version: "v1alpha1", Kind: "Workflow"}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(app, meta.RESTScopeNamespace)
+	c := fake.NewClientBuilder().WithRESTMapper(mapper).Build()
+	ctx := context.Background()
+
+	r.NoError(velaclient.CheckRequiredCRDs(ctx, c, []schema.GroupVersionKind{app}))
+	err := velaclient.CheckRequiredCRDs(ctx, c, []schema.GroupVersionKind{app, policy, workflow})
+	r.Error(err)
+	r.Contains(err.Error(), "kind Policy of core.oam.dev/v1alpha1 is not installed")
+	r.Contains(err.Error(), "kind Workflow of core.oam.dev/v1alpha1 is not installed")
+	r.NotContains(err.Error(), "Application")
+
+	// missing kinds are checked again after refreshing the discovery
+	mapper = meta.NewDefaultRESTMapper(nil)
+	c = fake.NewClientBuilder().WithRESTMapper(&resettableMapper{
+		DefaultRESTMapper: mapper,
+		onReset:           func() { mapper.Add(policy, meta.RESTScopeNamespace) },
+	}).Build()
+	r.NoError(velaclient.CheckRequiredCRDs(ctx, c, []schema.GroupVersionKind{policy}))
+}
@@ -55,6 +55,7 @@ func (d *delegatingClient) RESTMapper() meta.RESTMapper {
 // 1. for requests not in local cluster, disable cache
 // 2. for structured types, inherit the cache blacklist
 // 3. for unstructured types, use cache whitelist
+// 4. for requests pinned to resourceVersion, disable cache
 type delegatingReader struct {
 	CacheReader  client.Reader
 	ClientReader client.Reader
@@ -96,6 +97,8 @@ func (d *delegatingReader) List(ctx context.Context, list client.ObjectList, opt
 		return err
 	} else if cluster, _ := multicluster.ClusterFrom(ctx); !multicluster.IsLocal(cluster) || isUncached {
 		return d.ClientReader.List(ctx, list, opts...)
+	} else if _, pinned := pinnedResourceVersionFrom(ctx); pinned {
+		return d.ClientReader.List(ctx, list, opts...)
 	}
 	return d.CacheReader.List(ctx, list, opts...)
 }
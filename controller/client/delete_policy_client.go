@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WithDefaultDeletePolicy set the default propagation policy for the deletes
+// of DeletePolicyClient using the context
+func WithDefaultDeletePolicy(ctx context.Context, policy metav1.DeletionPropagation) context.Context {
+	return context.WithValue(ctx, defaultDeletePolicyContextKey, policy)
+}
+
+// DefaultDeletePolicyFrom extract the default propagation policy of deletes
+// from context
+func DefaultDeletePolicyFrom(ctx context.Context) (metav1.DeletionPropagation, bool) {
+	policy, ok := ctx.Value(defaultDeletePolicyContextKey).(metav1.DeletionPropagation)
+	return policy, ok
+}
+
+// DeletePolicyClient applies the default propagation policy in context to
+// Delete and DeleteAllOf, if the caller does not specify one
+type DeletePolicyClient struct {
+	client.Client
+}
+
+// WrapDeletePolicyClient wrap client with default propagation policy of deletes
+func WrapDeletePolicyClient(c client.Client) client.Client {
+	return &DeletePolicyClient{Client: c}
+}
+
+// Delete resource with the default propagation policy if absent
+func (c *DeletePolicyClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if policy, ok := DefaultDeletePolicyFrom(ctx); ok {
+		if o := (&client.DeleteOptions{}).ApplyOptions(opts); o.PropagationPolicy == nil {
+			opts = append(opts, client.PropagationPolicy(policy))
+		}
+	}
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+// DeleteAllOf resources with the default propagation policy if absent
+func (c *DeletePolicyClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	if policy, ok := DefaultDeletePolicyFrom(ctx); ok {
+		if o := (&client.DeleteAllOfOptions{}).ApplyOptions(opts); o.PropagationPolicy == nil {
+			opts = append(opts, client.PropagationPolicy(policy))
+		}
+	}
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
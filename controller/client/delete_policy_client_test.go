@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	velaclient "github.com/kubevela/pkg/controller/client"
+)
+
+type deleteOptionsClient struct {
+	client.Client
+	policies []*metav1.DeletionPropagation
+}
+
+func (c *deleteOptionsClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.policies = append(c.policies, (&client.DeleteOptions{}).ApplyOptions(opts).PropagationPolicy)
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *deleteOptionsClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	c.policies = append(c.policies, (&client.DeleteAllOfOptions{}).ApplyOptions(opts).PropagationPolicy)
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+func TestDeletePolicyClient(t *testing.T) {
+	r := require.New(t)
+	dc := &deleteOptionsClient{Client: fake.NewClientBuilder().WithObjects(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "b"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "c"}},
+	).Build()}
+	c := velaclient.WrapDeletePolicyClient(dc)
+	ctx := velaclient.WithDefaultDeletePolicy(context.Background(), metav1.DeletePropagationForeground)
+
+	r.NoError(c.Delete(context.Background(), &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a"}}))
+	r.Nil(dc.policies[0])
+	r.NoError(c.Delete(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "b"}}))
+	r.Equal(metav1.DeletePropagationForeground, *dc.policies[1])
+	r.NoError(c.Delete(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "c"}}, client.PropagationPolicy(metav1.DeletePropagationOrphan)))
+	r.Equal(metav1.DeletePropagationOrphan, *dc.policies[2])
+
+	r.NoError(c.DeleteAllOf(ctx, &corev1.ConfigMap{}, client.InNamespace("default")))
+	r.Equal(metav1.DeletePropagationForeground, *dc.policies[3])
+	r.NoError(c.DeleteAllOf(ctx, &corev1.ConfigMap{}, client.InNamespace("default"), client.PropagationPolicy(metav1.DeletePropagationBackground)))
+	r.Equal(metav1.DeletePropagationBackground, *dc.policies[4])
+}
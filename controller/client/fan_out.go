@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+	"github.com/kubevela/pkg/util/k8s"
+	"github.com/kubevela/pkg/util/slices"
+)
+
+const (
+	// ControllerFanOutApplyKey metrics key for counting the objects applied
+	// to each cluster by fan-out apply
+	ControllerFanOutApplyKey = "controller_fan_out_apply_total"
+	// ControllerFanOutApplyLatencyKey metrics key for recording time cost of
+	// applying the objects to each cluster by fan-out apply
+	ControllerFanOutApplyLatencyKey = "controller_fan_out_apply_time_seconds"
+)
+
+var (
+	// controllerFanOutApply the fan-out apply metrics
+	controllerFanOutApply = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerFanOutApplyKey,
+			Help:      "number of objects applied to each cluster by fan-out apply",
+		}, []string{"cluster", "result"})
+
+	// controllerFanOutApplyLatency the fan-out apply latency metrics
+	controllerFanOutApplyLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerFanOutApplyLatencyKey,
+			Help:      "duration of applying the objects to each cluster by fan-out apply",
+			Buckets:   metrics.FineGrainedBuckets,
+		}, []string{"cluster"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerFanOutApply, controllerFanOutApplyLatency)
+}
+
+// FanOutApply applies the objects to each cluster, with at most parallelism
+// clusters concurrently. If parallelism is not positive,
+// slices.DefaultParallelism is used. Within one cluster, the objects are
+// created or patched in order, and the failed ones do not stop the others.
+// The objects are copied for each cluster, so they are left unchanged. Once
+// the context is cancelled, the remaining objects are not applied and fail
+// with the context error. It returns the errors of the clusters having any
+// failed object.
+func FanOutApply(ctx context.Context, clusters map[string]client.Client, objs []client.Object, parallelism int) map[string][]error {
+	names := make([]string, 0, len(clusters))
+	for name := range clusters {
+		names = append(names, name)
+	}
+	if parallelism <= 0 {
+		parallelism = slices.DefaultParallelism
+	}
+	results := slices.ParMap(names, func(name string) []error {
+		begin := time.Now()
+		defer func() {
+			controllerFanOutApplyLatency.WithLabelValues(name).Observe(time.Since(begin).Seconds())
+		}()
+		var errs []error
+		for _, obj := range objs {
+			err := ctx.Err()
+			if err == nil {
+				_, err = createOrPatch(ctx, clusters[name], obj.DeepCopyObject().(client.Object))
+			}
+			if err != nil {
+				controllerFanOutApply.WithLabelValues(name, "error").Inc()
+				errs = append(errs, fmt.Errorf("failed to apply %s %s to cluster %s: %w", k8s.GetKindForObject(obj, false), client.ObjectKeyFromObject(obj), name, err))
+				continue
+			}
+			controllerFanOutApply.WithLabelValues(name, "success").Inc()
+		}
+		return errs
+	}, slices.Parallelism(parallelism))
+	clusterErrs := map[string][]error{}
+	for i, errs := range results {
+		if len(errs) > 0 {
+			clusterErrs[names[i]] = errs
+		}
+	}
+	return clusterErrs
+}
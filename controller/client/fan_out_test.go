@@ -0,0 +1,75 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// unavailableClient fails all the requests
+type unavailableClient struct {
+	client.Client
+}
+
+func (c *unavailableClient) Get(context.Context, client.ObjectKey, client.Object) error {
+	return fmt.Errorf("cluster unavailable")
+}
+
+func TestFanOutApply(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	healthy := fake.NewClientBuilder().Build()
+	clusters := map[string]client.Client{
+		"healthy":     healthy,
+		"unavailable": &unavailableClient{Client: fake.NewClientBuilder().Build()},
+	}
+	objs := []client.Object{
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a"}, Data: map[string]string{"key": "a"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "b"}, Data: map[string]string{"key": "b"}},
+	}
+	succeeded := testutil.ToFloat64(controllerFanOutApply.WithLabelValues("healthy", "success"))
+	failed := testutil.ToFloat64(controllerFanOutApply.WithLabelValues("unavailable", "error"))
+
+	errs := FanOutApply(ctx, clusters, objs, 0)
+	r.Len(errs, 1)
+	r.Len(errs["unavailable"], 2)
+	r.ErrorContains(errs["unavailable"][0], "failed to apply ConfigMap default/a to cluster unavailable: cluster unavailable")
+	for _, obj := range objs {
+		cm := &corev1.ConfigMap{}
+		r.NoError(healthy.Get(ctx, client.ObjectKeyFromObject(obj), cm))
+		r.Equal(obj.(*corev1.ConfigMap).Data, cm.Data)
+		r.Empty(obj.GetResourceVersion())
+	}
+	r.Equal(succeeded+2, testutil.ToFloat64(controllerFanOutApply.WithLabelValues("healthy", "success")))
+	r.Equal(failed+2, testutil.ToFloat64(controllerFanOutApply.WithLabelValues("unavailable", "error")))
+
+	// cancelled context stops applying
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	errs = FanOutApply(cancelled, map[string]client.Client{"healthy": healthy}, objs, 1)
+	r.Len(errs["healthy"], 2)
+	r.ErrorIs(errs["healthy"][0], context.Canceled)
+}
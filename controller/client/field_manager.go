@@ -0,0 +1,119 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"regexp"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/strings/slices"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+	"github.com/kubevela/pkg/util/k8s"
+)
+
+const (
+	// ControllerFieldManagerConflictsKey metrics key for counting the
+	// server-side apply conflicts with other field managers
+	ControllerFieldManagerConflictsKey = "controller_field_manager_conflicts_total"
+
+	// unknownFieldManager the manager label for managers not in KnownFieldManagers
+	unknownFieldManager = "other"
+)
+
+var (
+	// controllerFieldManagerConflicts the field manager conflicts metrics
+	controllerFieldManagerConflicts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerFieldManagerConflictsKey,
+			Help:      "number of server-side apply conflicts with other field managers",
+		}, []string{"manager", "kind"})
+)
+
+var (
+	// KnownFieldManagers the field managers to be recorded by name in the
+	// conflict metrics. Other managers are recorded as "other" to bound the
+	// metrics cardinality.
+	KnownFieldManagers []string
+	// MaxTrackedConflictFields the max number of conflicting fields tracked
+	// for detecting field flapping between managers
+	MaxTrackedConflictFields = 1024
+)
+
+var conflictManagerPattern = regexp.MustCompile(`conflicts? with "([^"]*)"`)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerFieldManagerConflicts)
+}
+
+// fieldManagerTracker remembers the last conflicting manager of each field
+type fieldManagerTracker struct {
+	mu       sync.Mutex
+	managers map[string]string
+}
+
+var conflictTracker = &fieldManagerTracker{managers: map[string]string{}}
+
+// track records the conflicting manager of the field and returns the previous one
+func (in *fieldManagerTracker) track(key string, manager string) (string, bool) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	prev, found := in.managers[key]
+	if !found && len(in.managers) >= MaxTrackedConflictFields {
+		in.managers = map[string]string{}
+	}
+	in.managers[key] = manager
+	return prev, found
+}
+
+// monitorFieldManagerConflicts records the field manager conflicts carried by
+// the server-side apply error. If a field conflicts with a different manager
+// from the last time, it is logged as flapping between managers.
+func monitorFieldManagerConflicts(obj client.Object, err error) {
+	statusErr := &kerrors.StatusError{}
+	if !kerrors.IsConflict(err) || !errors.As(err, &statusErr) || statusErr.ErrStatus.Details == nil {
+		return
+	}
+	kind := k8s.GetKindForObject(obj, true)
+	for _, cause := range statusErr.ErrStatus.Details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		manager := ""
+		if match := conflictManagerPattern.FindStringSubmatch(cause.Message); len(match) > 1 {
+			manager = match[1]
+		}
+		label := manager
+		if !slices.Contains(KnownFieldManagers, manager) {
+			label = unknownFieldManager
+		}
+		controllerFieldManagerConflicts.WithLabelValues(label, kind).Inc()
+		key := kind + "/" + client.ObjectKeyFromObject(obj).String() + ":" + cause.Field
+		if prev, found := conflictTracker.track(key, manager); found && prev != manager {
+			klog.InfoS("field flapping between managers", "kind", kind, "object", klog.KObj(obj),
+				"field", cause.Field, "previous", prev, "current", manager)
+		}
+	}
+}
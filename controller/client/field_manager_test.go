@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newApplyConflict(managers ...string) error {
+	var causes []metav1.StatusCause
+	for _, manager := range managers {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldManagerConflict,
+			Message: fmt.Sprintf("conflict with %q using v1", manager),
+			Field:   ".data.key",
+		})
+	}
+	return kerrors.NewApplyConflict(causes, "Apply failed")
+}
+
+type patchErrClient struct {
+	client.Client
+	err error
+}
+
+func (c *patchErrClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return c.err
+}
+
+func TestMonitorFieldManagerConflicts(t *testing.T) {
+	defer func(managers []string) { KnownFieldManagers = managers }(KnownFieldManagers)
+	KnownFieldManagers = []string{"kubectl"}
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example"}}
+	known := controllerFieldManagerConflicts.WithLabelValues("kubectl", "ConfigMap")
+	other := controllerFieldManagerConflicts.WithLabelValues(unknownFieldManager, "ConfigMap")
+	knownConflicts, otherConflicts := testutil.ToFloat64(known), testutil.ToFloat64(other)
+
+	monitorFieldManagerConflicts(cm, nil)
+	monitorFieldManagerConflicts(cm, kerrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "example", fmt.Errorf("rv changed")))
+	require.Equal(t, knownConflicts, testutil.ToFloat64(known))
+	require.Equal(t, otherConflicts, testutil.ToFloat64(other))
+
+	monitorFieldManagerConflicts(cm, newApplyConflict("kubectl", "helm"))
+	require.Equal(t, knownConflicts+1, testutil.ToFloat64(known))
+	require.Equal(t, otherConflicts+1, testutil.ToFloat64(other))
+
+	key := "ConfigMap/default/example:.data.key"
+	prev, found := conflictTracker.track(key, "kubectl")
+	require.True(t, found)
+	require.Equal(t, "helm", prev)
+
+	c := &monitorClient{&patchErrClient{Client: fake.NewClientBuilder().Build(), err: newApplyConflict("kubectl")}}
+	require.Error(t, c.Patch(context.Background(), cm, client.Apply))
+	require.Equal(t, knownConflicts+2, testutil.ToFloat64(known))
+}
+
+func TestFieldManagerTrackerBounded(t *testing.T) {
+	defer func(max int) { MaxTrackedConflictFields = max }(MaxTrackedConflictFields)
+	MaxTrackedConflictFields = 2
+	tracker := &fieldManagerTracker{managers: map[string]string{}}
+	for i := 0; i < 5; i++ {
+		tracker.track(fmt.Sprintf("field-%d", i), "manager")
+		require.LessOrEqual(t, len(tracker.managers), 2)
+	}
+}
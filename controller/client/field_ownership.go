@@ -0,0 +1,168 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/utils/strings/slices"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+	"github.com/kubevela/pkg/util/k8s"
+)
+
+const (
+	// ControllerFieldOwnershipConflictsKey metrics key for counting the fields
+	// owned by other managers which would be changed by non-apply writes
+	ControllerFieldOwnershipConflictsKey = "controller_field_ownership_conflicts_total"
+)
+
+var (
+	// controllerFieldOwnershipConflicts the field ownership conflicts metrics
+	controllerFieldOwnershipConflicts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerFieldOwnershipConflictsKey,
+			Help:      "number of fields owned by other managers which would be changed by non-apply writes",
+		}, []string{"manager", "kind"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerFieldOwnershipConflicts)
+}
+
+// DetectFieldOwnershipConflict returns the sorted paths of the fields owned by
+// managers other than ourManager in the managedFields of the live object,
+// which would be changed or removed by updating the live object to the
+// desired one. It can be used before updating objects which are also
+// server-side applied, to avoid stomping the fields applied by others. The
+// fields of subresources, like status, are ignored. The conflicting fields
+// are counted in metrics by manager, as in the field manager conflict metrics.
+func DetectFieldOwnershipConflict(live client.Object, desired client.Object, ourManager string) ([]string, error) {
+	liveContent, err := toUnstructuredContent(live)
+	if err != nil {
+		return nil, err
+	}
+	desiredContent, err := toUnstructuredContent(desired)
+	if err != nil {
+		return nil, err
+	}
+	kind := k8s.GetKindForObject(live, true)
+	conflicts := map[string]struct{}{}
+	for _, entry := range live.GetManagedFields() {
+		if entry.Manager == ourManager || entry.Subresource != "" || entry.FieldsV1 == nil {
+			continue
+		}
+		fields := &fieldpath.Set{}
+		if err = fields.FromJSON(bytes.NewReader(entry.FieldsV1.Raw)); err != nil {
+			return nil, err
+		}
+		label := entry.Manager
+		if !slices.Contains(KnownFieldManagers, label) {
+			label = unknownFieldManager
+		}
+		fields.Leaves().Iterate(func(path fieldpath.Path) {
+			liveVal, liveFound := lookupFieldPath(liveContent, path)
+			desiredVal, desiredFound := lookupFieldPath(desiredContent, path)
+			if liveFound == desiredFound && reflect.DeepEqual(liveVal, desiredVal) {
+				return
+			}
+			if _, found := conflicts[path.String()]; !found {
+				conflicts[path.String()] = struct{}{}
+				controllerFieldOwnershipConflicts.WithLabelValues(label, kind).Inc()
+			}
+		})
+	}
+	paths := make([]string, 0, len(conflicts))
+	for path := range conflicts {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// lookupFieldPath returns the value at the path of the managed fields in the
+// unstructured content
+func lookupFieldPath(content interface{}, path fieldpath.Path) (interface{}, bool) {
+	cur := content
+	for _, pe := range path {
+		switch {
+		case pe.FieldName != nil:
+			m, ok := cur.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if cur, ok = m[*pe.FieldName]; !ok {
+				return nil, false
+			}
+		case pe.Index != nil:
+			items, ok := cur.([]interface{})
+			if !ok || *pe.Index >= len(items) {
+				return nil, false
+			}
+			cur = items[*pe.Index]
+		default:
+			items, ok := cur.([]interface{})
+			if !ok {
+				return nil, false
+			}
+			found := false
+			for _, item := range items {
+				if matchPathElement(item, pe) {
+					cur, found = item, true
+					break
+				}
+			}
+			if !found {
+				return nil, false
+			}
+		}
+	}
+	return cur, true
+}
+
+// matchPathElement check if the list item is selected by the key or value of
+// the path element. Values are compared by their JSON forms, as numbers are
+// decoded differently in managed fields.
+func matchPathElement(item interface{}, pe fieldpath.PathElement) bool {
+	if pe.Value != nil {
+		return equalJSON(item, (*pe.Value).Unstructured())
+	}
+	m, ok := item.(map[string]interface{})
+	if !ok || pe.Key == nil {
+		return false
+	}
+	for _, field := range *pe.Key {
+		if !equalJSON(m[field.Name], field.Value.Unstructured()) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalJSON(a, b interface{}) bool {
+	aBytes, aErr := json.Marshal(a)
+	bBytes, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && string(aBytes) == string(bBytes)
+}
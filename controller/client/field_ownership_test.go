@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestDetectFieldOwnershipConflict(t *testing.T) {
+	r := require.New(t)
+	defer func(managers []string) { KnownFieldManagers = managers }(KnownFieldManagers)
+	KnownFieldManagers = []string{"hpa"}
+	live := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default", Name: "example", Labels: map[string]string{"app": "example"},
+			ManagedFields: []metav1.ManagedFieldsEntry{{
+				Manager: "vela", Operation: metav1.ManagedFieldsOperationApply,
+				FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{"f:app":{}}},"f:spec":{"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"main\"}":{".":{},"f:image":{},"f:name":{}}}}}}}`)},
+			}, {
+				Manager: "hpa", Operation: metav1.ManagedFieldsOperationApply,
+				FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{}}}`)},
+			}, {
+				Manager: "kubectl", Operation: metav1.ManagedFieldsOperationUpdate,
+				FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"main\"}":{"f:imagePullPolicy":{}}}}}}}`)},
+			}, {
+				Manager: "kubectl", Operation: metav1.ManagedFieldsOperationUpdate, Subresource: "status",
+				FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:status":{"f:replicas":{}}}`)},
+			}},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: pointer.Int32(3),
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name: "main", Image: "nginx:1", ImagePullPolicy: corev1.PullAlways,
+			}}}},
+		},
+		Status: appsv1.DeploymentStatus{Replicas: 3},
+	}
+
+	hpaConflicts := testutil.ToFloat64(controllerFieldOwnershipConflicts.WithLabelValues("hpa", "Deployment"))
+	otherConflicts := testutil.ToFloat64(controllerFieldOwnershipConflicts.WithLabelValues("other", "Deployment"))
+
+	// changing our own fields and the status does not conflict
+	desired := live.DeepCopy()
+	desired.Labels["app"] = "changed"
+	desired.Spec.Template.Spec.Containers[0].Image = "nginx:2"
+	desired.Status.Replicas = 1
+	conflicts, err := DetectFieldOwnershipConflict(live, desired, "vela")
+	r.NoError(err)
+	r.Empty(conflicts)
+
+	// changing or removing the fields owned by others conflicts
+	desired.Spec.Replicas = pointer.Int32(1)
+	desired.Spec.Template.Spec.Containers[0].ImagePullPolicy = ""
+	conflicts, err = DetectFieldOwnershipConflict(live, desired, "vela")
+	r.NoError(err)
+	r.Equal([]string{".spec.replicas", `.spec.template.spec.containers[name="main"].imagePullPolicy`}, conflicts)
+	r.Equal(hpaConflicts+1, testutil.ToFloat64(controllerFieldOwnershipConflicts.WithLabelValues("hpa", "Deployment")))
+	r.Equal(otherConflicts+1, testutil.ToFloat64(controllerFieldOwnershipConflicts.WithLabelValues("other", "Deployment")))
+}
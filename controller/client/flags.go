@@ -21,6 +21,7 @@ import "github.com/spf13/pflag"
 // AddFlags add flags for default controller client
 func AddFlags(set *pflag.FlagSet) {
 	AddTimeoutControllerClientFlags(set)
+	AddMonitorControllerClientFlags(set)
 }
 
 // AddTimeoutControllerClientFlags add flags for default timeout controller client
@@ -38,3 +39,19 @@ func AddTimeoutControllerClientFlags(set *pflag.FlagSet) {
 		DefaultTimeoutClientOptions.MutatingRequestTimeout,
 		"The timeout value for controller client mutating (update, patch, delete) requests.")
 }
+
+// AddMonitorControllerClientFlags add flags for default monitor controller client
+func AddMonitorControllerClientFlags(set *pflag.FlagSet) {
+	set.DurationVarP(&CacheWaitThreshold,
+		"controller-client-cache-wait-threshold", "",
+		CacheWaitThreshold,
+		"The threshold for controller cache requests to be recorded as blocked. Set to 0 to disable.")
+	set.StringSliceVarP(&KnownFieldManagers,
+		"controller-client-known-field-managers", "",
+		KnownFieldManagers,
+		"The field managers to be recorded by name in the field manager conflict metrics. Others are recorded as other.")
+	set.BoolVarP(&RecordRBACRules,
+		"controller-client-record-rbac-rules", "",
+		RecordRBACRules,
+		"Record the rbac rules observed from the controller client calls.")
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+	"github.com/kubevela/pkg/util/k8s"
+)
+
+const (
+	// ControllerGCLagKey metrics key for recording time cost of garbage
+	// collecting children after owner deleted
+	ControllerGCLagKey = "controller_gc_lag_seconds"
+	// ControllerGCTimeoutKey metrics key for counting garbage collections
+	// which do not finish before timeout
+	ControllerGCTimeoutKey = "controller_gc_timeout_total"
+)
+
+var (
+	// controllerGCLag the garbage collection lag metrics
+	controllerGCLag = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerGCLagKey,
+			Help:      "time cost for garbage collecting children after owner deleted",
+			Buckets:   metrics.FineGrainedBuckets,
+		}, []string{"kind", "apiVersion"})
+
+	// controllerGCTimeout the garbage collection timeout metrics
+	controllerGCTimeout = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerGCTimeoutKey,
+			Help:      "number of garbage collections not finished before timeout",
+		}, []string{"kind", "apiVersion"})
+)
+
+var (
+	// GCLagPollInterval the interval for polling remaining children in MonitorGCLag
+	GCLagPollInterval = time.Second
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerGCLag, controllerGCTimeout)
+}
+
+// MonitorGCLag should be called after the owner is deleted. It polls the
+// children of childGVK owned by the owner until all of them are garbage
+// collected, and records the time cost. If children still remain after
+// timeout, the timeout will be recorded and an error will be returned.
+// Children are identified by the owner reference to the owner UID, in the
+// namespace of the owner, so children of other owners with the same name are
+// not counted. The owner object is taken instead of its key, as the UID cannot
+// be retrieved by the key once the owner is deleted, and only its namespace,
+// name and UID are used. Cancelling ctx is not recorded as timeout.
+func MonitorGCLag(ctx context.Context, c client.Client, owner client.Object, childGVK schema.GroupVersionKind, timeout time.Duration) error {
+	begin := time.Now()
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ownerKey := client.ObjectKeyFromObject(owner)
+	labels := []string{childGVK.Kind, k8s.NormalizeAPIVersion(childGVK.GroupVersion().String())}
+	var remaining int
+	err := wait.PollImmediateUntilWithContext(ctx, GCLagPollInterval, func(ctx context.Context) (bool, error) {
+		children := &unstructured.UnstructuredList{}
+		children.SetGroupVersionKind(childGVK.GroupVersion().WithKind(childGVK.Kind + "List"))
+		if err := c.List(ctx, children, client.InNamespace(ownerKey.Namespace)); err != nil {
+			return false, err
+		}
+		remaining = 0
+		for _, child := range children.Items {
+			for _, ref := range child.GetOwnerReferences() {
+				if ref.UID == owner.GetUID() {
+					remaining++
+					break
+				}
+			}
+		}
+		return remaining == 0, nil
+	})
+	switch {
+	case err == nil:
+		controllerGCLag.WithLabelValues(labels...).Observe(time.Since(begin).Seconds())
+		return nil
+	case parent.Err() != nil:
+		return parent.Err()
+	case errors.Is(err, wait.ErrWaitTimeout):
+		controllerGCTimeout.WithLabelValues(labels...).Inc()
+		return fmt.Errorf("%d %s children of %s not garbage collected after %s", remaining, childGVK.Kind, ownerKey, timeout)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMonitorGCLag(t *testing.T) {
+	defer func(interval time.Duration) { GCLagPollInterval = interval }(GCLagPollInterval)
+	GCLagPollInterval = 10 * time.Millisecond
+
+	newChild := func(name string, owner string, uid types.UID) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            name,
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "v1", Kind: "Secret", Name: owner, UID: uid}},
+		}}
+	}
+	children := []client.Object{newChild("a", "owner", "uid"), newChild("b", "owner", "uid")}
+	// children of the recreated owner or other kinds with the same name are not counted
+	c := fake.NewClientBuilder().WithObjects(append(children, newChild("c", "other", "other-uid"), newChild("d", "owner", "recreated-uid"))...).Build()
+	ctx := context.Background()
+	owner := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "owner", UID: "uid"}}
+	gvk := corev1.SchemeGroupVersion.WithKind("ConfigMap")
+	lag := controllerGCLag.WithLabelValues("ConfigMap", "v1")
+	lags, timeouts := getSampleCount(t, lag), testutil.ToFloat64(controllerGCTimeout.WithLabelValues("ConfigMap", "v1"))
+
+	go func() {
+		for _, child := range children {
+			time.Sleep(50 * time.Millisecond)
+			_ = c.Delete(ctx, child)
+		}
+	}()
+	require.NoError(t, MonitorGCLag(ctx, c, owner, gvk, 5*time.Second))
+	require.Equal(t, lags+1, getSampleCount(t, lag))
+
+	other := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other", UID: "other-uid"}}
+	err := MonitorGCLag(ctx, c, other, gvk, 50*time.Millisecond)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "1 ConfigMap children of default/other not garbage collected")
+	require.Equal(t, timeouts+1, testutil.ToFloat64(controllerGCTimeout.WithLabelValues("ConfigMap", "v1")))
+
+	// cancellation is not recorded as timeout
+	cancelled, cancel := context.WithCancel(ctx)
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		cancel()
+	}()
+	require.ErrorIs(t, MonitorGCLag(cancelled, c, other, gvk, 5*time.Second), context.Canceled)
+	require.Equal(t, timeouts+1, testutil.ToFloat64(controllerGCTimeout.WithLabelValues("ConfigMap", "v1")))
+}
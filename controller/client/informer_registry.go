@@ -0,0 +1,160 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+	"github.com/kubevela/pkg/util/k8s"
+)
+
+const (
+	// ControllerInformersDeduplicatedKey metrics key for counting the informer
+	// requests served by informers already created for other controllers
+	ControllerInformersDeduplicatedKey = "controller_informers_deduplicated_total"
+)
+
+var (
+	// controllerInformersDeduplicated the deduplicated informers metrics
+	controllerInformersDeduplicated = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerInformersDeduplicatedKey,
+			Help:      "number of informer requests served by informers shared with other controllers",
+		}, []string{"kind", "apiVersion"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerInformersDeduplicated)
+}
+
+// informerKey identifies the informer. Typed and unstructured objects of the
+// same kind use separate informers.
+type informerKey struct {
+	gvk          schema.GroupVersionKind
+	unstructured bool
+}
+
+// informerCall the pending creation of an informer, which is shared by the
+// concurrent requests of the same informer
+type informerCall struct {
+	done chan struct{}
+	err  error
+}
+
+// InformerRegistry shares the informers of the cache across the controllers,
+// so that only one informer is created for each kind. It records the
+// controllers using each informer, and the requests served by informers
+// created for other controllers are counted in metrics.
+type InformerRegistry struct {
+	cache  cache.Cache
+	scheme *runtime.Scheme
+
+	mu          sync.Mutex
+	informers   map[informerKey]cache.Informer
+	controllers map[informerKey]sets.String
+	pending     map[informerKey]*informerCall
+}
+
+// NewInformerRegistry creates the informer registry for the cache. The scheme
+// is used to resolve the kinds of typed objects.
+func NewInformerRegistry(c cache.Cache, scheme *runtime.Scheme) *InformerRegistry {
+	return &InformerRegistry{
+		cache:       c,
+		scheme:      scheme,
+		informers:   map[informerKey]cache.Informer{},
+		controllers: map[informerKey]sets.String{},
+		pending:     map[informerKey]*informerCall{},
+	}
+}
+
+// GetInformer returns the informer for the object, creating it if no
+// controller has requested the kind before. The lock is not held while
+// creating the informer, which may wait for the cache to sync, and the
+// concurrent requests of the same informer wait for the pending creation.
+func (in *InformerRegistry) GetInformer(ctx context.Context, controller string, obj client.Object) (cache.Informer, error) {
+	gvk, err := apiutil.GVKForObject(obj, in.scheme)
+	if err != nil {
+		return nil, err
+	}
+	key := informerKey{gvk: gvk, unstructured: k8s.IsUnstructuredObject(obj)}
+	in.mu.Lock()
+	for {
+		if informer, found := in.informers[key]; found {
+			if !in.controllers[key].Has(controller) {
+				in.controllers[key].Insert(controller)
+				controllerInformersDeduplicated.WithLabelValues(gvk.Kind, k8s.NormalizeAPIVersion(gvk.GroupVersion().String())).Inc()
+			}
+			in.mu.Unlock()
+			return informer, nil
+		}
+		call, found := in.pending[key]
+		if !found {
+			break
+		}
+		in.mu.Unlock()
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if call.err != nil {
+			return nil, call.err
+		}
+		in.mu.Lock()
+	}
+	call := &informerCall{done: make(chan struct{})}
+	in.pending[key] = call
+	in.mu.Unlock()
+
+	informer, err := in.cache.GetInformer(ctx, obj)
+	in.mu.Lock()
+	delete(in.pending, key)
+	if err == nil {
+		in.informers[key] = informer
+		in.controllers[key] = sets.NewString(controller)
+	}
+	in.mu.Unlock()
+	call.err = err
+	close(call.done)
+	return informer, err
+}
+
+// Informers returns the number of informers created by the registry
+func (in *InformerRegistry) Informers() int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return len(in.informers)
+}
+
+// Controllers returns the controllers using the informer of the kind
+func (in *InformerRegistry) Controllers(gvk schema.GroupVersionKind, unstructured bool) []string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.controllers[informerKey{gvk: gvk, unstructured: unstructured}].List()
+}
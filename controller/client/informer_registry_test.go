@@ -0,0 +1,135 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type countingInformers struct {
+	*informertest.FakeInformers
+	// block if set, the creation of ConfigMap informers waits for it
+	block chan struct{}
+	err   error
+
+	mu      sync.Mutex
+	created int
+}
+
+func (c *countingInformers) GetInformer(ctx context.Context, obj client.Object) (cache.Informer, error) {
+	c.mu.Lock()
+	c.created++
+	err := c.err
+	c.mu.Unlock()
+	if _, ok := obj.(*corev1.ConfigMap); ok && c.block != nil {
+		<-c.block
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.FakeInformers.GetInformer(ctx, obj)
+}
+
+func (c *countingInformers) getCreated() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.created
+}
+
+func TestInformerRegistry(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	informers := &countingInformers{FakeInformers: &informertest.FakeInformers{Scheme: scheme.Scheme}}
+	registry := NewInformerRegistry(informers, scheme.Scheme)
+	deduplicated := testutil.ToFloat64(controllerInformersDeduplicated.WithLabelValues("ConfigMap", "v1"))
+
+	a, err := registry.GetInformer(ctx, "app", &corev1.ConfigMap{})
+	r.NoError(err)
+	b, err := registry.GetInformer(ctx, "workflow", &corev1.ConfigMap{})
+	r.NoError(err)
+	_, err = registry.GetInformer(ctx, "workflow", &corev1.ConfigMap{})
+	r.NoError(err)
+	r.Same(a, b)
+	r.Equal(1, informers.created)
+	r.Equal(1, registry.Informers())
+	r.Equal([]string{"app", "workflow"}, registry.Controllers(corev1.SchemeGroupVersion.WithKind("ConfigMap"), false))
+	r.Equal(deduplicated+1, testutil.ToFloat64(controllerInformersDeduplicated.WithLabelValues("ConfigMap", "v1")))
+
+	_, err = registry.GetInformer(ctx, "app", &corev1.Secret{})
+	r.NoError(err)
+	r.Equal(2, informers.created)
+	r.Equal(2, registry.Informers())
+}
+
+func TestInformerRegistryConcurrent(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	informers := &countingInformers{
+		FakeInformers: &informertest.FakeInformers{Scheme: scheme.Scheme},
+		block:         make(chan struct{}),
+		err:           fmt.Errorf("not synced"),
+	}
+	registry := NewInformerRegistry(informers, scheme.Scheme)
+
+	// other informers are not blocked by the pending creation
+	errs := make(chan error)
+	go func() {
+		_, err := registry.GetInformer(ctx, "app", &corev1.ConfigMap{})
+		errs <- err
+	}()
+	r.Eventually(func() bool { return informers.getCreated() == 1 }, time.Second, 5*time.Millisecond)
+	_, err := registry.GetInformer(ctx, "app", &corev1.Secret{})
+	r.Error(err)
+	r.Equal(2, informers.getCreated())
+	informers.block <- struct{}{}
+	r.Error(<-errs)
+	r.Equal(0, registry.Informers())
+
+	// failed creation is retried, and concurrent requests share the creation
+	informers.mu.Lock()
+	informers.err = nil
+	informers.mu.Unlock()
+	close(informers.block)
+	var wg sync.WaitGroup
+	results := make([]cache.Informer, 3)
+	for i, controller := range []string{"app", "workflow", "addon"} {
+		wg.Add(1)
+		go func(i int, controller string) {
+			defer wg.Done()
+			informer, err := registry.GetInformer(ctx, controller, &corev1.ConfigMap{})
+			r.NoError(err)
+			results[i] = informer
+		}(i, controller)
+	}
+	wg.Wait()
+	r.Equal(3, informers.getCreated())
+	r.Same(results[0], results[1])
+	r.Same(results[0], results[2])
+	r.Equal([]string{"addon", "app", "workflow"}, registry.Controllers(corev1.SchemeGroupVersion.WithKind("ConfigMap"), false))
+}
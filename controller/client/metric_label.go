@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"k8s.io/utils/strings/slices"
+)
+
+const (
+	// overflowMetricLabelValue the label value for override values exceeding
+	// the max distinct values of the label
+	overflowMetricLabelValue = "other"
+)
+
+// overridableMetricLabels the labels of the client metrics which can be
+// overridden by WithMetricLabelOverride
+var overridableMetricLabels = []string{"controller", "cluster"}
+
+// metricLabelOverride the registered schema of one overridable label
+type metricLabelOverride struct {
+	maxValues int
+	values    map[string]struct{}
+}
+
+var (
+	metricLabelOverridesMu sync.Mutex
+	metricLabelOverrides   = map[string]*metricLabelOverride{}
+)
+
+// RegisterMetricLabelOverride allows the label of the client metrics to be
+// overridden by WithMetricLabelOverride. Only the controller and cluster
+// labels can be overridden. To bound the metrics cardinality, at most
+// maxValues distinct values are recorded, further values are recorded as
+// "other".
+func RegisterMetricLabelOverride(key string, maxValues int) error {
+	if !slices.Contains(overridableMetricLabels, key) {
+		return fmt.Errorf("metric label %s cannot be overridden, overridable labels: %v", key, overridableMetricLabels)
+	}
+	if maxValues <= 0 {
+		return fmt.Errorf("max values for metric label %s must be positive", key)
+	}
+	metricLabelOverridesMu.Lock()
+	defer metricLabelOverridesMu.Unlock()
+	metricLabelOverrides[key] = &metricLabelOverride{maxValues: maxValues, values: map[string]struct{}{}}
+	return nil
+}
+
+// WithMetricLabelOverride overrides the label of the client metrics for the
+// requests using the context, such as grouping by tenant instead of
+// controller. Overrides of labels not registered by
+// RegisterMetricLabelOverride are ignored.
+func WithMetricLabelOverride(ctx context.Context, key string, value string) context.Context {
+	overrides := map[string]string{}
+	if parent, ok := ctx.Value(metricLabelOverrideContextKey).(map[string]string); ok {
+		for k, v := range parent {
+			overrides[k] = v
+		}
+	}
+	overrides[key] = value
+	return context.WithValue(ctx, metricLabelOverrideContextKey, overrides)
+}
+
+// metricLabelOverrideFrom returns the bounded override value of the label in
+// context, false if not overridden
+func metricLabelOverrideFrom(ctx context.Context, key string) (string, bool) {
+	overrides, ok := ctx.Value(metricLabelOverrideContextKey).(map[string]string)
+	if !ok {
+		return "", false
+	}
+	value, ok := overrides[key]
+	if !ok {
+		return "", false
+	}
+	metricLabelOverridesMu.Lock()
+	defer metricLabelOverridesMu.Unlock()
+	schema, registered := metricLabelOverrides[key]
+	if !registered {
+		return "", false
+	}
+	if _, found := schema.values[value]; !found {
+		if len(schema.values) >= schema.maxValues {
+			return overflowMetricLabelValue, true
+		}
+		schema.values[value] = struct{}{}
+	}
+	return value, true
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMetricLabelOverride(t *testing.T) {
+	r := require.New(t)
+	r.Error(RegisterMetricLabelOverride("verb", 10))
+	r.Error(RegisterMetricLabelOverride("controller", 0))
+	r.NoError(RegisterMetricLabelOverride("controller", 1))
+	defer func() {
+		metricLabelOverridesMu.Lock()
+		defer metricLabelOverridesMu.Unlock()
+		delete(metricLabelOverrides, "controller")
+	}()
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example"}}
+	c := &monitorClient{fake.NewClientBuilder().WithObjects(cm).Build()}
+	latency := func(controller string) uint64 {
+		return getSampleCount(t, controllerClientRequestLatency.WithLabelValues(controller, "", "Get", "ConfigMap", "v1", "false"))
+	}
+	ctx := WithMetricLabelOverride(context.Background(), "controller", "tenant-a")
+	// overrides of unregistered labels are ignored
+	ctx = WithMetricLabelOverride(ctx, "cluster", "tenant-cluster")
+	r.NoError(c.Get(ctx, client.ObjectKeyFromObject(cm), &corev1.ConfigMap{}))
+	r.Equal(uint64(1), latency("tenant-a"))
+
+	// values exceeding the max distinct values are recorded as other
+	ctx = WithMetricLabelOverride(context.Background(), "controller", "tenant-b")
+	r.NoError(c.Get(ctx, client.ObjectKeyFromObject(cm), &corev1.ConfigMap{}))
+	r.Equal(uint64(0), latency("tenant-b"))
+	r.Equal(uint64(1), latency(overflowMetricLabelValue))
+}
@@ -18,10 +18,13 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -37,6 +40,15 @@ const (
 	// ControllerClientRequestLatencyKey metrics key for recording time cost
 	// of controller client requests
 	ControllerClientRequestLatencyKey = "controller_client_request_time_seconds"
+	// ControllerCacheWaitLatencyKey metrics key for recording time cost of
+	// cache requests which block longer than CacheWaitThreshold
+	ControllerCacheWaitLatencyKey = "controller_cache_wait_seconds"
+	// ControllerClientPinnedRVRequestsKey metrics key for counting controller
+	// client requests pinned to the exact resourceVersion
+	ControllerClientPinnedRVRequestsKey = "controller_client_pinned_rv_requests_total"
+	// ControllerWriteBytesKey metrics key for recording payload size of
+	// controller client write requests
+	ControllerWriteBytesKey = "controller_write_bytes"
 )
 
 var (
@@ -50,44 +62,166 @@ var (
 			Help:      "client request duration for kubevela controllers",
 			Buckets:   metrics.FineGrainedBuckets,
 		}, []string{"controller", "cluster", "verb", "kind", "apiVersion", "unstructured"})
+
+	// controllerCacheWaitLatency the cache request latency metrics
+	// It only records the monitorCache function calls which take longer than
+	// CacheWaitThreshold, so that cache contention can be told apart from
+	// the regular cache reads
+	controllerCacheWaitLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerCacheWaitLatencyKey,
+			Help:      "blocked cache request duration for kubevela controllers",
+			Buckets:   metrics.FineGrainedBuckets,
+		}, []string{"controller", "verb", "kind", "apiVersion"})
+
+	// controllerClientPinnedRVRequests the pinned resourceVersion request
+	// metrics, which are rare compared to the regular requests
+	controllerClientPinnedRVRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerClientPinnedRVRequestsKey,
+			Help:      "number of client requests pinned to the exact resourceVersion for kubevela controllers",
+		}, []string{"controller", "verb", "kind"})
+
+	// controllerWriteBytes the write payload size metrics
+	// It is only recorded when verbose metrics are enabled, as the objects
+	// need to be serialized for calculating the size.
+	controllerWriteBytes = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerWriteBytesKey,
+			Help:      "client write request payload size in bytes for kubevela controllers",
+			Buckets:   prometheus.ExponentialBuckets(256, 4, 8),
+		}, []string{"verb", "kind"})
+)
+
+var (
+	// otelClientRequestLatency mirrors the client request latency metrics in
+	// OTel, which is recorded if an OTel meter provider is registered
+	otelClientRequestLatency = metrics.NewOTelHistogram(ControllerClientRequestLatencyKey, "client request duration for kubevela controllers")
+)
+
+var (
+	// CacheWaitThreshold the threshold for cache requests to be recorded in
+	// the cache wait metrics. If not positive, cache wait will not be recorded.
+	CacheWaitThreshold = 100 * time.Millisecond
 )
 
 func init() {
-	ctrlmetrics.Registry.MustRegister(controllerClientRequestLatency)
+	ctrlmetrics.Registry.MustRegister(controllerClientRequestLatency, controllerCacheWaitLatency, controllerClientPinnedRVRequests, controllerWriteBytes)
 }
 
 // monitor creates a callback to call when function ends
-// It reports the execution duration for the function call
+// It reports the execution duration for the function call. If the context
+// carries a reconcile trace, the call is recorded as a step as well.
 func monitor(ctx context.Context, verb string, obj runtime.Object) func() {
 	begin := time.Now()
-	cluster, _ := multicluster.ClusterFrom(ctx)
+	cluster, overridden := metricLabelOverrideFrom(ctx, "cluster")
+	if !overridden {
+		cluster, _ = multicluster.ClusterFrom(ctx)
+	}
+	_, pinned := pinnedResourceVersionFrom(ctx)
 	return func() {
-		v := time.Since(begin).Seconds()
+		d := time.Since(begin)
+		kind := k8s.GetKindForObject(obj, true)
+		controller := getControllerLabel(ctx)
+		apiVersion := k8s.NormalizeAPIVersion(obj.GetObjectKind().GroupVersionKind().GroupVersion().String())
 		controllerClientRequestLatency.WithLabelValues(
-			velaruntime.GetControllerInCaller(),
+			controller,
 			cluster,
 			verb,
-			k8s.GetKindForObject(obj, true),
-			obj.GetObjectKind().GroupVersionKind().GroupVersion().String(),
+			kind,
+			apiVersion,
 			fmt.Sprintf("%t", k8s.IsUnstructuredObject(obj)),
-		).Observe(v)
+		).Observe(d.Seconds())
+		if pinned {
+			controllerClientPinnedRVRequests.WithLabelValues(controller, verb, kind).Inc()
+		}
+		otelClientRequestLatency.Record(ctx, d.Seconds(),
+			attribute.String("controller", controller),
+			attribute.String("cluster", cluster),
+			attribute.String("verb", verb),
+			attribute.String("kind", kind),
+			attribute.String("apiVersion", apiVersion),
+		)
+		if trace := velaruntime.TraceFrom(ctx); trace != nil {
+			trace.Record(verb+" "+kind, begin, d)
+		}
+	}
+}
+
+// getControllerLabel returns the controller label, which is overridden by
+// WithMetricLabelOverride or extracted from the callers
+func getControllerLabel(ctx context.Context) string {
+	if controller, overridden := metricLabelOverrideFrom(ctx, "controller"); overridden {
+		return controller
+	}
+	return velaruntime.GetControllerInCaller()
+}
+
+// monitorWait creates a callback to call when cache function ends
+// It reports the execution duration for the function call if the duration
+// exceeds CacheWaitThreshold
+func monitorWait(ctx context.Context, verb string, obj runtime.Object) func() {
+	begin := time.Now()
+	return func() {
+		d := time.Since(begin)
+		if CacheWaitThreshold <= 0 || d < CacheWaitThreshold {
+			return
+		}
+		controllerCacheWaitLatency.WithLabelValues(
+			getControllerLabel(ctx),
+			verb,
+			k8s.GetKindForObject(obj, true),
+			k8s.NormalizeAPIVersion(obj.GetObjectKind().GroupVersionKind().GroupVersion().String()),
+		).Observe(d.Seconds())
+	}
+}
+
+// monitorWriteSize records the payload size of the write request if verbose
+// metrics are enabled. For patch requests, the patch data is measured.
+// Otherwise, the JSON serialized object is measured.
+func monitorWriteSize(verb string, obj client.Object, patch client.Patch) {
+	if !metrics.IsVerbose() {
+		return
+	}
+	var bs []byte
+	var err error
+	if patch != nil {
+		bs, err = patch.Data(obj)
+	} else {
+		bs, err = json.Marshal(obj)
+	}
+	if err != nil {
+		return
 	}
+	controllerWriteBytes.WithLabelValues(verb, k8s.GetKindForObject(obj, true)).Observe(float64(len(bs)))
 }
 
 // monitorCache records time costs in metrics when execute function calls
 type monitorCache struct {
 	cache.Cache
+	// scheme and mapper are used for recording the observed rbac rules
+	scheme *runtime.Scheme
+	mapper meta.RESTMapper
 }
 
 func (c *monitorCache) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
 	cb := monitor(ctx, "GetCache", obj)
 	defer cb()
+	wcb := monitorWait(ctx, "GetCache", obj)
+	defer wcb()
+	observeRBACRule(c.scheme, c.mapper, obj, "", "list", "watch")
 	return c.Cache.Get(ctx, key, obj)
 }
 
 func (c *monitorCache) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
 	cb := monitor(ctx, "ListCache", list)
 	defer cb()
+	wcb := monitorWait(ctx, "ListCache", list)
+	defer wcb()
+	observeRBACRule(c.scheme, c.mapper, list, "", "list", "watch")
 	return c.Cache.List(ctx, list, opts...)
 }
 
@@ -99,62 +233,80 @@ type monitorClient struct {
 func (c *monitorClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
 	cb := monitor(ctx, "Get", obj)
 	defer cb()
+	observeRBACRule(c.Client.Scheme(), c.Client.RESTMapper(), obj, "", "get")
 	return c.Client.Get(ctx, key, obj)
 }
 
 func (c *monitorClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
 	cb := monitor(ctx, "List", list)
 	defer cb()
+	observeRBACRule(c.Client.Scheme(), c.Client.RESTMapper(), list, "", "list")
 	return c.Client.List(ctx, list, opts...)
 }
 
 func (c *monitorClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	monitorWriteSize("Create", obj, nil)
 	cb := monitor(ctx, "Create", obj)
 	defer cb()
+	observeRBACRule(c.Client.Scheme(), c.Client.RESTMapper(), obj, "", "create")
 	return c.Client.Create(ctx, obj, opts...)
 }
 
 func (c *monitorClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
 	cb := monitor(ctx, "Delete", obj)
 	defer cb()
+	observeRBACRule(c.Client.Scheme(), c.Client.RESTMapper(), obj, "", "delete")
 	return c.Client.Delete(ctx, obj, opts...)
 }
 
 func (c *monitorClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	monitorWriteSize("Update", obj, nil)
 	cb := monitor(ctx, "Update", obj)
 	defer cb()
+	observeRBACRule(c.Client.Scheme(), c.Client.RESTMapper(), obj, "", "update")
 	return c.Client.Update(ctx, obj, opts...)
 }
 
 func (c *monitorClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	monitorWriteSize("Patch", obj, patch)
 	cb := monitor(ctx, "Patch", obj)
 	defer cb()
-	return c.Client.Patch(ctx, obj, patch, opts...)
+	observeRBACRule(c.Client.Scheme(), c.Client.RESTMapper(), obj, "", "patch")
+	err := c.Client.Patch(ctx, obj, patch, opts...)
+	monitorFieldManagerConflicts(obj, err)
+	return err
 }
 
 func (c *monitorClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
 	cb := monitor(ctx, "DeleteAllOf", obj)
 	defer cb()
+	observeRBACRule(c.Client.Scheme(), c.Client.RESTMapper(), obj, "", "deletecollection")
 	return c.Client.DeleteAllOf(ctx, obj, opts...)
 }
 
 func (c *monitorClient) Status() client.StatusWriter {
-	return &monitorStatusWriter{c.Client.Status()}
+	return &monitorStatusWriter{StatusWriter: c.Client.Status(), scheme: c.Client.Scheme(), mapper: c.Client.RESTMapper()}
 }
 
 // monitorStatusWriter records time costs in metrics when execute function calls
 type monitorStatusWriter struct {
 	client.StatusWriter
+	scheme *runtime.Scheme
+	mapper meta.RESTMapper
 }
 
 func (w *monitorStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	monitorWriteSize("StatusUpdate", obj, nil)
 	cb := monitor(ctx, "StatusUpdate", obj)
 	defer cb()
+	observeRBACRule(w.scheme, w.mapper, obj, "status", "update")
 	return w.StatusWriter.Update(ctx, obj, opts...)
 }
 
 func (w *monitorStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	monitorWriteSize("StatusPatch", obj, patch)
 	cb := monitor(ctx, "StatusPatch", obj)
 	defer cb()
+	observeRBACRule(w.scheme, w.mapper, obj, "status", "patch")
 	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
 }
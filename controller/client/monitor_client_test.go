@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+	velaruntime "github.com/kubevela/pkg/util/runtime"
+)
+
+type slowCache struct {
+	cache.Cache
+	delay time.Duration
+}
+
+func (c *slowCache) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	time.Sleep(c.delay)
+	return nil
+}
+
+func (c *slowCache) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	time.Sleep(c.delay)
+	return nil
+}
+
+func getSampleCount(t *testing.T, o prometheus.Observer) uint64 {
+	m := &dto.Metric{}
+	require.NoError(t, o.(prometheus.Metric).Write(m))
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestMonitorCacheWait(t *testing.T) {
+	defer func(threshold time.Duration) { CacheWaitThreshold = threshold }(CacheWaitThreshold)
+	CacheWaitThreshold = 20 * time.Millisecond
+	ctx := context.Background()
+	getObserver := controllerCacheWaitLatency.WithLabelValues("", "GetCache", "ConfigMap", "")
+	listObserver := controllerCacheWaitLatency.WithLabelValues("", "ListCache", "ConfigMap", "")
+	gets, lists := getSampleCount(t, getObserver), getSampleCount(t, listObserver)
+
+	fast := &monitorCache{Cache: &slowCache{}}
+	require.NoError(t, fast.Get(ctx, client.ObjectKey{Name: "example"}, &corev1.ConfigMap{}))
+	require.NoError(t, fast.List(ctx, &corev1.ConfigMapList{}))
+	require.Equal(t, gets, getSampleCount(t, getObserver))
+	require.Equal(t, lists, getSampleCount(t, listObserver))
+
+	slow := &monitorCache{Cache: &slowCache{delay: 30 * time.Millisecond}}
+	require.NoError(t, slow.Get(ctx, client.ObjectKey{Name: "example"}, &corev1.ConfigMap{}))
+	require.NoError(t, slow.List(ctx, &corev1.ConfigMapList{}))
+	require.Equal(t, gets+1, getSampleCount(t, getObserver))
+	require.Equal(t, lists+1, getSampleCount(t, listObserver))
+
+	CacheWaitThreshold = 0
+	require.NoError(t, slow.Get(ctx, client.ObjectKey{Name: "example"}, &corev1.ConfigMap{}))
+	require.Equal(t, gets+1, getSampleCount(t, getObserver))
+}
+
+func TestMonitorClientTrace(t *testing.T) {
+	defer func(threshold time.Duration) { velaruntime.ReconcileTraceThreshold = threshold }(velaruntime.ReconcileTraceThreshold)
+	velaruntime.ReconcileTraceThreshold = 0
+	ctx, trace := velaruntime.NewReconcileTrace(context.Background())
+	c := &monitorClient{fake.NewClientBuilder().Build()}
+	require.NoError(t, c.List(ctx, &corev1.ConfigMapList{}))
+	var lines []string
+	trace.Dump(funcr.New(func(prefix, args string) { lines = append(lines, args) }, funcr.Options{}))
+	require.Equal(t, 2, len(lines))
+	require.Contains(t, lines[1], `"step"="reconcile/List ConfigMap"`)
+}
+
+func getSampleSum(t *testing.T, o prometheus.Observer) float64 {
+	m := &dto.Metric{}
+	require.NoError(t, o.(prometheus.Metric).Write(m))
+	return m.GetHistogram().GetSampleSum()
+}
+
+func TestMonitorWriteSize(t *testing.T) {
+	ctx := context.Background()
+	c := &monitorClient{fake.NewClientBuilder().Build()}
+	observer := controllerWriteBytes.WithLabelValues("Create", "ConfigMap")
+	patchObserver := controllerWriteBytes.WithLabelValues("Patch", "ConfigMap")
+	writes, writeBytes, patchBytes := getSampleCount(t, observer), getSampleSum(t, observer), getSampleSum(t, patchObserver)
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "disabled"}}
+	require.NoError(t, c.Create(ctx, cm))
+	require.Equal(t, writes, getSampleCount(t, observer))
+
+	metrics.MetricsDetailLevel = metrics.DetailLevelVerbose
+	defer func() { metrics.MetricsDetailLevel = metrics.DetailLevelDefault }()
+	cm = &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "enabled"},
+		Data:       map[string]string{"key": strings.Repeat("x", 1024)},
+	}
+	bs, err := json.Marshal(cm)
+	require.NoError(t, err)
+	require.NoError(t, c.Create(ctx, cm))
+	require.Equal(t, writes+1, getSampleCount(t, observer))
+	require.Equal(t, writeBytes+float64(len(bs)), getSampleSum(t, observer))
+
+	patch := client.RawPatch(client.Merge.Type(), []byte(`{"data":{"key":"value"}}`))
+	require.NoError(t, c.Patch(ctx, cm, patch))
+	require.Equal(t, patchBytes+24, getSampleSum(t, patchObserver))
+}
+
+func BenchmarkMonitorWriteSize(b *testing.B) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example"},
+		Data:       map[string]string{"key": strings.Repeat("x", 64*1024)},
+	}
+	for _, level := range []metrics.DetailLevel{metrics.DetailLevelDefault, metrics.DetailLevelVerbose} {
+		b.Run(fmt.Sprintf("level-%d", level), func(b *testing.B) {
+			metrics.MetricsDetailLevel = level
+			defer func() { metrics.MetricsDetailLevel = metrics.DetailLevelDefault }()
+			for i := 0; i < b.N; i++ {
+				monitorWriteSize("Update", cm, nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubevela/pkg/util/k8s"
+)
+
+// NamePolicyClient rejects creating resources whose names do not follow the
+// NamePolicy
+type NamePolicyClient struct {
+	client.Client
+	Policy k8s.NamePolicy
+}
+
+// Create resource if its name follows the NamePolicy. If the name is empty,
+// the generateName is validated instead, leaving room for the random suffix
+// appended by the apiserver.
+func (c *NamePolicyClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if obj.GetName() == "" && obj.GetGenerateName() != "" {
+		if err := k8s.ValidateGenerateName(obj.GetGenerateName(), c.Policy); err != nil {
+			return fmt.Errorf("invalid generateName for %s %s/%s: %w", k8s.GetKindForObject(obj, false), obj.GetNamespace(), obj.GetGenerateName(), err)
+		}
+	} else if err := k8s.ValidateName(obj.GetName(), c.Policy); err != nil {
+		return fmt.Errorf("invalid name for %s %s: %w", k8s.GetKindForObject(obj, false), client.ObjectKeyFromObject(obj), err)
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+// WrapNamePolicyClient wrap client with NamePolicy
+func WrapNamePolicyClient(c client.Client, policy k8s.NamePolicy) client.Client {
+	return &NamePolicyClient{Client: c, Policy: policy}
+}
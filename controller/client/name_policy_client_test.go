@@ -0,0 +1,60 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	velaclient "github.com/kubevela/pkg/controller/client"
+	"github.com/kubevela/pkg/util/k8s"
+)
+
+func TestNamePolicyClient(t *testing.T) {
+	c := velaclient.WrapNamePolicyClient(fake.NewClientBuilder().Build(), k8s.NamePolicy{
+		Prefix:            "vela-",
+		AllowedCharacters: regexp.MustCompile(`[a-z0-9-]`),
+	})
+	ctx := context.Background()
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example"}}
+	err := c.Create(ctx, cm)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `must have prefix "vela-"`)
+	require.Error(t, c.Get(ctx, client.ObjectKeyFromObject(cm), &corev1.ConfigMap{}))
+
+	cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vela-example"}}
+	require.NoError(t, c.Create(ctx, cm))
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(cm), &corev1.ConfigMap{}))
+
+	// generateName is validated with room for the random suffix
+	c = velaclient.WrapNamePolicyClient(fake.NewClientBuilder().Build(), k8s.NamePolicy{
+		Prefix:    "vela-",
+		MaxLength: 12,
+	})
+	err = c.Create(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", GenerateName: "example-"}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `generateName "example-" must have prefix "vela-"`)
+	require.Contains(t, err.Error(), "produces names of 13 characters long, exceeding max length 12")
+	require.NoError(t, c.Create(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", GenerateName: "vela-e-"}}))
+}
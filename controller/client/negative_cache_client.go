@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+)
+
+const (
+	// ControllerNegativeCacheRequestsKey metrics key for counting the Gets
+	// through the negative cache, by whether the cached NotFound is hit
+	ControllerNegativeCacheRequestsKey = "controller_negative_cache_requests_total"
+)
+
+var (
+	// NegativeCacheMaxEntries the max number of NotFound results cached by
+	// one NegativeCacheClient. The least recently used ones are evicted.
+	NegativeCacheMaxEntries = 10000
+)
+
+var (
+	// controllerNegativeCacheRequests the negative cache metrics
+	controllerNegativeCacheRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerNegativeCacheRequestsKey,
+			Help:      "number of gets through the negative cache, by result of hit or miss",
+		}, []string{"kind", "result"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerNegativeCacheRequests)
+}
+
+// negativeCacheKey identifies the object known not to exist in the cluster
+type negativeCacheKey struct {
+	cluster string
+	gvk     schema.GroupVersionKind
+	key     client.ObjectKey
+}
+
+// NegativeCacheClient caches the NotFound results of Get for the negative
+// TTL, so that repeatedly getting objects known not to exist, like waiting
+// for a dependency, does not hit the apiserver. The NotFound results are
+// cached per cluster in the context. Writes to an object invalidate its cached
+// NotFound.
+type NegativeCacheClient struct {
+	client.Client
+	ttl      time.Duration
+	notFound *cache.LRUExpireCache
+}
+
+// NewNegativeCacheClient wrap client with NotFound results cached for the
+// negativeTTL
+func NewNegativeCacheClient(c client.Client, negativeTTL time.Duration) client.Client {
+	return newNegativeCacheClient(c, negativeTTL, cache.NewLRUExpireCache(NegativeCacheMaxEntries))
+}
+
+func newNegativeCacheClient(c client.Client, negativeTTL time.Duration, notFound *cache.LRUExpireCache) *NegativeCacheClient {
+	return &NegativeCacheClient{Client: c, ttl: negativeTTL, notFound: notFound}
+}
+
+func (c *NegativeCacheClient) cacheKey(ctx context.Context, key client.ObjectKey, obj client.Object) (negativeCacheKey, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	return negativeCacheKey{cluster: clusterFrom(ctx), gvk: gvk, key: key}, err
+}
+
+func (c *NegativeCacheClient) invalidate(ctx context.Context, obj client.Object) {
+	if key, err := c.cacheKey(ctx, client.ObjectKeyFromObject(obj), obj); err == nil {
+		c.notFound.Remove(key)
+	}
+}
+
+// Get resource, returning the cached NotFound if the object is known not to
+// exist within the negative TTL
+func (c *NegativeCacheClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	_key, err := c.cacheKey(ctx, key, obj)
+	if err != nil {
+		return c.Client.Get(ctx, key, obj)
+	}
+	if cached, found := c.notFound.Get(_key); found {
+		controllerNegativeCacheRequests.WithLabelValues(_key.gvk.Kind, "hit").Inc()
+		return cached.(error)
+	}
+	controllerNegativeCacheRequests.WithLabelValues(_key.gvk.Kind, "miss").Inc()
+	err = c.Client.Get(ctx, key, obj)
+	if kerrors.IsNotFound(err) {
+		c.notFound.Add(_key, err, c.ttl)
+	}
+	return err
+}
+
+// Create resource and invalidate its cached NotFound
+func (c *NegativeCacheClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	defer c.invalidate(ctx, obj)
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+// Delete resource and invalidate its cached NotFound
+func (c *NegativeCacheClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	defer c.invalidate(ctx, obj)
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+// Update resource and invalidate its cached NotFound
+func (c *NegativeCacheClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	defer c.invalidate(ctx, obj)
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+// Patch resource and invalidate its cached NotFound, as server-side apply
+// may create the object
+func (c *NegativeCacheClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	defer c.invalidate(ctx, obj)
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/cache"
+	testingclock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kubevela/pkg/multicluster"
+)
+
+func TestNegativeCacheClient(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	clock := testingclock.NewFakeClock(time.Now())
+	cc := &countingClient{Client: fake.NewClientBuilder().Build()}
+	c := newNegativeCacheClient(cc, time.Minute, cache.NewLRUExpireCacheWithClock(10, clock))
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example"}}
+	key := client.ObjectKeyFromObject(cm)
+	hits := testutil.ToFloat64(controllerNegativeCacheRequests.WithLabelValues("ConfigMap", "hit"))
+
+	r.True(kerrors.IsNotFound(c.Get(ctx, key, &corev1.ConfigMap{})))
+	r.True(kerrors.IsNotFound(c.Get(ctx, key, &corev1.ConfigMap{})))
+	r.Equal(1, cc.gets)
+	r.Equal(hits+1, testutil.ToFloat64(controllerNegativeCacheRequests.WithLabelValues("ConfigMap", "hit")))
+
+	// expired after the negative TTL
+	clock.Step(2 * time.Minute)
+	r.True(kerrors.IsNotFound(c.Get(ctx, key, &corev1.ConfigMap{})))
+	r.Equal(2, cc.gets)
+
+	// invalidated by writes
+	r.NoError(c.Create(ctx, cm))
+	r.NoError(c.Get(ctx, key, &corev1.ConfigMap{}))
+	r.Equal(3, cc.gets)
+
+	// found objects are not cached
+	r.NoError(c.Get(ctx, key, &corev1.ConfigMap{}))
+	r.Equal(4, cc.gets)
+}
+
+func TestNegativeCacheClientMultiCluster(t *testing.T) {
+	r := require.New(t)
+	a, b := fake.NewClientBuilder().Build(), fake.NewClientBuilder().Build()
+	c := NewNegativeCacheClient(&clusterRoutingClient{Client: a, clusters: map[string]client.Client{"a": a, "b": b}}, time.Minute)
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example"}}
+	key := client.ObjectKeyFromObject(cm)
+	ctxA := multicluster.WithCluster(context.Background(), "a")
+	ctxB := multicluster.WithCluster(context.Background(), "b")
+
+	// NotFound in one cluster is not returned for the other
+	r.NoError(b.Create(context.Background(), cm.DeepCopy()))
+	r.True(kerrors.IsNotFound(c.Get(ctxA, key, &corev1.ConfigMap{})))
+	r.NoError(c.Get(ctxB, key, &corev1.ConfigMap{}))
+
+	// writes invalidate the cached NotFound of their own cluster only
+	r.True(kerrors.IsNotFound(c.Get(ctxA, key, &corev1.ConfigMap{})))
+	r.NoError(b.Delete(context.Background(), cm.DeepCopy()))
+	r.True(kerrors.IsNotFound(c.Get(ctxB, key, &corev1.ConfigMap{})))
+	r.NoError(a.Create(context.Background(), cm.DeepCopy()))
+	r.NoError(c.Update(ctxA, cm.DeepCopy()))
+	r.NoError(c.Get(ctxA, key, &corev1.ConfigMap{}))
+	r.True(kerrors.IsNotFound(c.Get(ctxB, key, &corev1.ConfigMap{})))
+}
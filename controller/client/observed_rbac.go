@@ -0,0 +1,121 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+var (
+	// RecordRBACRules enables recording the (group, resource, verb) tuples
+	// called by the controller client, which can be retrieved by
+	// ObservedRBACRules
+	RecordRBACRules = false
+	// RBACRulesMaxTracked the max number of (group, resource, verb) tuples to
+	// be recorded. Tuples observed after reaching the limit are dropped.
+	RBACRulesMaxTracked = 1000
+)
+
+// rbacRule the observed (group, resource, verb) tuple
+type rbacRule struct {
+	group    string
+	resource string
+	verb     string
+}
+
+// rbacRuleRecorder accumulates the observed rbac rules
+type rbacRuleRecorder struct {
+	mu    sync.Mutex
+	rules map[rbacRule]struct{}
+}
+
+var observedRBACRules = &rbacRuleRecorder{rules: map[rbacRule]struct{}{}}
+
+// observeRBACRule records the verbs called on the resource of the object if
+// RecordRBACRules is enabled. For subresources, the resource is recorded as
+// <resource>/<subresource>. Objects which cannot be mapped are ignored.
+func observeRBACRule(scheme *runtime.Scheme, mapper meta.RESTMapper, obj runtime.Object, subresource string, verbs ...string) {
+	if !RecordRBACRules || scheme == nil || mapper == nil {
+		return
+	}
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return
+	}
+	if meta.IsListType(obj) {
+		gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	}
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return
+	}
+	resource := mapping.Resource.Resource
+	if subresource != "" {
+		resource += "/" + subresource
+	}
+	observedRBACRules.mu.Lock()
+	defer observedRBACRules.mu.Unlock()
+	for _, verb := range verbs {
+		rule := rbacRule{group: gvk.Group, resource: resource, verb: verb}
+		if _, found := observedRBACRules.rules[rule]; !found && len(observedRBACRules.rules) < RBACRulesMaxTracked {
+			observedRBACRules.rules[rule] = struct{}{}
+		}
+	}
+}
+
+// ObservedRBACRules returns the rbac rules observed from the calls of the
+// controller client since RecordRBACRules is enabled. The verbs of the same
+// resource are merged into one rule. Reads served by the cache are recorded
+// as list and watch. The rules are sorted by group and resource, so that
+// they can be diffed against the granted rbac rules.
+func ObservedRBACRules() []rbacv1.PolicyRule {
+	observedRBACRules.mu.Lock()
+	verbs := map[rbacRule][]string{}
+	for rule := range observedRBACRules.rules {
+		key := rbacRule{group: rule.group, resource: rule.resource}
+		verbs[key] = append(verbs[key], rule.verb)
+	}
+	observedRBACRules.mu.Unlock()
+
+	keys := make([]rbacRule, 0, len(verbs))
+	for key := range verbs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].group != keys[j].group {
+			return keys[i].group < keys[j].group
+		}
+		return keys[i].resource < keys[j].resource
+	})
+	rules := make([]rbacv1.PolicyRule, 0, len(keys))
+	for _, key := range keys {
+		sort.Strings(verbs[key])
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{key.group},
+			Resources: []string{key.resource},
+			Verbs:     verbs[key],
+		})
+	}
+	return rules
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestObservedRBACRules(t *testing.T) {
+	defer func(enabled bool, limit int) {
+		RecordRBACRules, RBACRulesMaxTracked = enabled, limit
+		observedRBACRules.rules = map[rbacRule]struct{}{}
+	}(RecordRBACRules, RBACRulesMaxTracked)
+	r := require.New(t)
+	ctx := context.Background()
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Pod"), meta.RESTScopeNamespace)
+	mapper.Add(appsv1.SchemeGroupVersion.WithKind("Deployment"), meta.RESTScopeNamespace)
+	c := &monitorClient{fake.NewClientBuilder().WithRESTMapper(mapper).Build()}
+	mCache := &monitorCache{Cache: &slowCache{}, scheme: scheme.Scheme, mapper: mapper}
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example"}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example"}}
+
+	// calls are not recorded until enabled
+	r.NoError(c.Create(ctx, cm))
+	r.Empty(ObservedRBACRules())
+
+	RecordRBACRules = true
+	r.NoError(c.Get(ctx, client.ObjectKeyFromObject(cm), cm))
+	r.NoError(c.Update(ctx, cm))
+	r.NoError(c.Delete(ctx, cm))
+	r.NoError(c.List(ctx, &corev1.ConfigMapList{}))
+	r.NoError(c.Create(ctx, pod))
+	r.NoError(c.Status().Update(ctx, pod))
+	r.NoError(c.DeleteAllOf(ctx, &appsv1.Deployment{}, client.InNamespace("default")))
+	r.NoError(mCache.Get(ctx, client.ObjectKeyFromObject(pod), pod))
+	// unknown resources are ignored
+	r.NoError(c.List(ctx, &corev1.SecretList{}))
+	r.Equal([]rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"delete", "get", "list", "update"}},
+		{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"create", "list", "watch"}},
+		{APIGroups: []string{""}, Resources: []string{"pods/status"}, Verbs: []string{"update"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"deletecollection"}},
+	}, ObservedRBACRules())
+
+	// tuples beyond the limit are dropped
+	RBACRulesMaxTracked = 9
+	r.NoError(c.Patch(ctx, pod, client.RawPatch("application/merge-patch+json", []byte(`{}`))))
+	r.NotContains(ObservedRBACRules()[1].Verbs, "patch")
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// volatileMetadataFields the metadata fields maintained by the apiserver,
+// which are ignored when building patches
+var volatileMetadataFields = []string{
+	"resourceVersion", "uid", "generation", "creationTimestamp",
+	"deletionTimestamp", "deletionGracePeriodSeconds", "managedFields", "selfLink",
+}
+
+// BuildMergePatch builds a JSON merge patch which only contains the fields in
+// desired that differ from live, so that the patch touches as few fields as
+// possible. Fields absent in desired are left unchanged, so that the fields
+// maintained by others, such as finalizers, owner references and server
+// defaults, are kept. Lists are patched as a whole, but only if desired is
+// not a subset of live. The status and the volatile metadata maintained by
+// the apiserver are ignored. Both objects are converted to canonical JSON
+// before comparison.
+func BuildMergePatch(live, desired client.Object) (client.Patch, error) {
+	liveMap, err := toCanonicalMap(live)
+	if err != nil {
+		return nil, err
+	}
+	desiredMap, err := toCanonicalMap(desired)
+	if err != nil {
+		return nil, err
+	}
+	patch := diffMap(liveMap, desiredMap)
+	if patch == nil {
+		patch = map[string]interface{}{}
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	return client.RawPatch(types.MergePatchType, data), nil
+}
+
+// toCanonicalMap converts the object into JSON compatible map, dropping the
+// status and volatile metadata
+func toCanonicalMap(obj client.Object) (map[string]interface{}, error) {
+	bs, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	if err = json.Unmarshal(bs, &m); err != nil {
+		return nil, err
+	}
+	delete(m, "status")
+	if metadata, ok := m["metadata"].(map[string]interface{}); ok {
+		for _, field := range volatileMetadataFields {
+			delete(metadata, field)
+		}
+	}
+	return m, nil
+}
+
+// diffMap returns the fields in desired which are not a subset of live, nil
+// if no difference
+func diffMap(live, desired map[string]interface{}) map[string]interface{} {
+	var diff map[string]interface{}
+	for key, desiredVal := range desired {
+		liveVal := live[key]
+		if isSubset(desiredVal, liveVal) {
+			continue
+		}
+		desiredSub, desiredIsMap := desiredVal.(map[string]interface{})
+		liveSub, liveIsMap := liveVal.(map[string]interface{})
+		if desiredIsMap && liveIsMap {
+			sub := diffMap(liveSub, desiredSub)
+			if sub == nil {
+				continue
+			}
+			desiredVal = sub
+		}
+		if diff == nil {
+			diff = map[string]interface{}{}
+		}
+		diff[key] = desiredVal
+	}
+	return diff
+}
+
+// jsonPatchOperation the operation of JSON patch defined in RFC 6902
+type jsonPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// MarshalJSON drops the value for remove operations, while keeping the zero
+// values for other operations
+func (in jsonPatchOperation) MarshalJSON() ([]byte, error) {
+	if in.Op == "remove" {
+		return json.Marshal(map[string]string{"op": in.Op, "path": in.Path})
+	}
+	type operation jsonPatchOperation
+	return json.Marshal(operation(in))
+}
+
+// PatchBuilder builds JSON patch from field paths, so that the path segments
+// are always escaped correctly. It can be used as client.Patch directly.
+type PatchBuilder struct {
+	operations []jsonPatchOperation
+}
+
+var _ client.Patch = &PatchBuilder{}
+
+// NewPatchBuilder creates an empty PatchBuilder
+func NewPatchBuilder() *PatchBuilder {
+	return &PatchBuilder{}
+}
+
+// Set the field at path to the value. The field is added if not exists,
+// otherwise replaced. For arrays, the segment "-" appends to the end.
+func (in *PatchBuilder) Set(value interface{}, path ...string) *PatchBuilder {
+	in.operations = append(in.operations, jsonPatchOperation{Op: "add", Path: toJSONPointer(path), Value: value})
+	return in
+}
+
+// Remove the field at path
+func (in *PatchBuilder) Remove(path ...string) *PatchBuilder {
+	in.operations = append(in.operations, jsonPatchOperation{Op: "remove", Path: toJSONPointer(path)})
+	return in
+}
+
+// Type .
+func (in *PatchBuilder) Type() types.PatchType {
+	return types.JSONPatchType
+}
+
+// Data returns the JSON patch, the object is not used
+func (in *PatchBuilder) Data(client.Object) ([]byte, error) {
+	if in.operations == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(in.operations)
+}
+
+// jsonPointerEscaper escapes the path segment as defined in RFC 6901. The
+// replacement is done in a single pass, so the escaped "/" is not escaped again.
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func toJSONPointer(path []string) string {
+	sb := strings.Builder{}
+	for _, segment := range path {
+		sb.WriteString("/")
+		sb.WriteString(jsonPointerEscaper.Replace(segment))
+	}
+	return sb.String()
+}
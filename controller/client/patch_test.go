@@ -0,0 +1,144 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	velaclient "github.com/kubevela/pkg/controller/client"
+)
+
+func TestBuildMergePatch(t *testing.T) {
+	live := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            "example",
+			ResourceVersion: "10",
+			UID:             "uid",
+			Labels:          map[string]string{"app": "example", "version": "v1"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: pointer.Int32(1),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "example"}},
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "main", Image: "nginx:1"}},
+			}},
+		},
+		Status: appsv1.DeploymentStatus{Replicas: 1},
+	}
+	desired := live.DeepCopy()
+	desired.ResourceVersion = ""
+	desired.UID = ""
+	desired.Status = appsv1.DeploymentStatus{}
+	desired.Labels["version"] = "v2"
+	desired.Spec.Template.Spec.Containers[0].Image = "nginx:2"
+
+	patch, err := velaclient.BuildMergePatch(live, desired)
+	r := require.New(t)
+	r.NoError(err)
+	r.Equal(types.MergePatchType, patch.Type())
+	data, err := patch.Data(desired)
+	r.NoError(err)
+	r.JSONEq(`{
+		"metadata": {"labels": {"version": "v2"}},
+		"spec": {"template": {"spec": {"containers": [{"name": "main", "image": "nginx:2", "resources": {}}]}}}
+	}`, string(data))
+
+	patch, err = velaclient.BuildMergePatch(live, live.DeepCopy())
+	r.NoError(err)
+	data, err = patch.Data(live)
+	r.NoError(err)
+	r.Equal(`{}`, string(data))
+
+	c := fake.NewClientBuilder().WithObjects(live.DeepCopy()).Build()
+	ctx := context.Background()
+	patch, err = velaclient.BuildMergePatch(live, desired)
+	r.NoError(err)
+	obj := live.DeepCopy()
+	r.NoError(c.Patch(ctx, obj, patch))
+	r.NoError(c.Get(ctx, client.ObjectKeyFromObject(live), obj))
+	r.Equal("v2", obj.Labels["version"])
+	r.Equal("example", obj.Labels["app"])
+	r.Equal("nginx:2", obj.Spec.Template.Spec.Containers[0].Image)
+	r.Equal(int32(1), *obj.Spec.Replicas)
+
+	// fields maintained by others or defaulted by the server are kept
+	live = obj.DeepCopy()
+	live.Finalizers = []string{"example.com/finalizer"}
+	live.Annotations = map[string]string{"owner": "other-controller"}
+	live.Spec.Template.Spec.Containers[0].ImagePullPolicy = corev1.PullIfNotPresent
+	r.NoError(c.Update(ctx, live))
+	patch, err = velaclient.BuildMergePatch(live, desired)
+	r.NoError(err)
+	data, err = patch.Data(desired)
+	r.NoError(err)
+	r.Equal(`{}`, string(data))
+	desired.Labels["version"] = "v3"
+	patch, err = velaclient.BuildMergePatch(live, desired)
+	r.NoError(err)
+	r.NoError(c.Patch(ctx, live, patch))
+	r.NoError(c.Get(ctx, client.ObjectKeyFromObject(live), obj))
+	r.Equal("v3", obj.Labels["version"])
+	r.Equal([]string{"example.com/finalizer"}, obj.Finalizers)
+	r.Equal(map[string]string{"owner": "other-controller"}, obj.Annotations)
+	r.Equal(corev1.PullIfNotPresent, obj.Spec.Template.Spec.Containers[0].ImagePullPolicy)
+}
+
+func TestPatchBuilder(t *testing.T) {
+	r := require.New(t)
+	patch := velaclient.NewPatchBuilder().
+		Set("v2", "metadata", "labels", "app.oam.dev/version").
+		Set(0, "spec", "replicas").
+		Set(map[string]interface{}{"name": "sidecar"}, "spec", "template", "spec", "containers", "-").
+		Remove("metadata", "annotations", "example.com/a~b")
+	r.Equal(types.JSONPatchType, patch.Type())
+	data, err := patch.Data(nil)
+	r.NoError(err)
+	r.JSONEq(`[
+		{"op": "add", "path": "/metadata/labels/app.oam.dev~1version", "value": "v2"},
+		{"op": "add", "path": "/spec/replicas", "value": 0},
+		{"op": "add", "path": "/spec/template/spec/containers/-", "value": {"name": "sidecar"}},
+		{"op": "remove", "path": "/metadata/annotations/example.com~1a~0b"}
+	]`, string(data))
+
+	data, err = velaclient.NewPatchBuilder().Data(nil)
+	r.NoError(err)
+	r.Equal("[]", string(data))
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "default",
+		Name:        "example",
+		Labels:      map[string]string{"a/b": "old"},
+		Annotations: map[string]string{"c~d": "value"},
+	}}
+	c := fake.NewClientBuilder().WithObjects(cm).Build()
+	ctx := context.Background()
+	r.NoError(c.Patch(ctx, cm, velaclient.NewPatchBuilder().Set("new", "metadata", "labels", "a/b").Remove("metadata", "annotations", "c~d")))
+	r.NoError(c.Get(ctx, client.ObjectKeyFromObject(cm), cm))
+	r.Equal("new", cm.Labels["a/b"])
+	r.NotContains(cm.Annotations, "c~d")
+}
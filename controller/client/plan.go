@@ -0,0 +1,143 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// ReconcilePlan the categorized objects for reconciling a set of objects
+type ReconcilePlan struct {
+	// ToCreate the desired objects which do not exist
+	ToCreate []client.Object
+	// ToUpdate the desired objects which exist but differ from the current ones
+	ToUpdate []client.Object
+	// ToDelete the current objects which are no longer desired
+	ToDelete []client.Object
+	// Unchanged the desired objects which are identical to the current ones
+	Unchanged []client.Object
+}
+
+type planKey struct {
+	gvk schema.GroupVersionKind
+	key client.ObjectKey
+}
+
+// PlanReconcile compares the desired objects with the current objects and
+// computes the plan to reconcile the current objects to the desired ones,
+// without performing any writes. Objects are matched by kind, namespace and
+// name. Only the fields other than the type meta, metadata and status, such
+// as spec and data, plus the labels and annotations are compared. A desired
+// object is unchanged if all its compared fields equal the current ones, so
+// the fields defaulted by the apiserver or added by others in the current
+// object do not count. The client is used to resolve the kinds of typed
+// objects.
+func PlanReconcile(_ context.Context, c client.Client, desired []client.Object, current []client.Object) (ReconcilePlan, error) {
+	plan := ReconcilePlan{}
+	currentContents := map[planKey]map[string]interface{}{}
+	for _, obj := range current {
+		key, err := getPlanKey(c, obj)
+		if err != nil {
+			return plan, err
+		}
+		if currentContents[key], err = toSpecContent(obj); err != nil {
+			return plan, err
+		}
+	}
+	desiredKeys := map[planKey]struct{}{}
+	for _, obj := range desired {
+		key, err := getPlanKey(c, obj)
+		if err != nil {
+			return plan, err
+		}
+		if _, found := desiredKeys[key]; found {
+			return plan, fmt.Errorf("duplicate desired object %s %s", key.gvk.Kind, key.key)
+		}
+		desiredKeys[key] = struct{}{}
+		currentContent, found := currentContents[key]
+		if !found {
+			plan.ToCreate = append(plan.ToCreate, obj)
+			continue
+		}
+		content, err := toSpecContent(obj)
+		if err != nil {
+			return plan, err
+		}
+		if isSubset(content, currentContent) {
+			plan.Unchanged = append(plan.Unchanged, obj)
+		} else {
+			plan.ToUpdate = append(plan.ToUpdate, obj)
+		}
+	}
+	for _, obj := range current {
+		key, _ := getPlanKey(c, obj)
+		if _, found := desiredKeys[key]; !found {
+			plan.ToDelete = append(plan.ToDelete, obj)
+		}
+	}
+	return plan, nil
+}
+
+func getPlanKey(c client.Client, obj client.Object) (planKey, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return planKey{}, err
+	}
+	return planKey{gvk: gvk, key: client.ObjectKeyFromObject(obj)}, nil
+}
+
+// hashObjectSpec computes the hash of the object content returned by
+// toSpecContent in canonical JSON
+func hashObjectSpec(obj client.Object) (string, error) {
+	m, err := toSpecContent(obj)
+	if err != nil {
+		return "", err
+	}
+	bs, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(bs)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// toSpecContent returns the desired state of the object, i.e. the fields
+// other than the type meta, metadata and status, such as spec and data, plus
+// the labels and annotations. Other metadata like owner references and
+// finalizers are usually maintained by the apiserver or other controllers.
+func toSpecContent(obj client.Object) (map[string]interface{}, error) {
+	m, err := toCanonicalMap(obj)
+	if err != nil {
+		return nil, err
+	}
+	metadata, _ := m["metadata"].(map[string]interface{})
+	m = copyMapWithout(m, "apiVersion", "kind", "metadata")
+	for _, field := range []string{"labels", "annotations"} {
+		if v, found := metadata[field]; found {
+			m[field] = v
+		}
+	}
+	return m, nil
+}
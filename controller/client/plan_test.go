@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	velaclient "github.com/kubevela/pkg/controller/client"
+)
+
+func TestPlanReconcile(t *testing.T) {
+	unchanged := &unstructured.Unstructured{}
+	unchanged.SetAPIVersion("v1")
+	unchanged.SetKind("ConfigMap")
+	unchanged.SetNamespace("default")
+	unchanged.SetName("unchanged")
+	unchanged.Object["data"] = map[string]interface{}{"key": "value"}
+	desired := []client.Object{
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "create"}, Data: map[string]string{"key": "value"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "update"}, Data: map[string]string{"key": "new"}},
+		unchanged,
+	}
+	current := []client.Object{
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "update", ResourceVersion: "1"}, Data: map[string]string{"key": "old"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "unchanged", ResourceVersion: "2"}, Data: map[string]string{"key": "value"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "delete", ResourceVersion: "3"}, Data: map[string]string{"key": "value"}},
+	}
+	c := fake.NewClientBuilder().Build()
+	ctx := context.Background()
+	r := require.New(t)
+	plan, err := velaclient.PlanReconcile(ctx, c, desired, current)
+	r.NoError(err)
+	names := func(objs []client.Object) (s []string) {
+		for _, obj := range objs {
+			s = append(s, obj.GetName())
+		}
+		return s
+	}
+	r.Equal([]string{"create"}, names(plan.ToCreate))
+	r.Equal([]string{"update"}, names(plan.ToUpdate))
+	r.Equal([]string{"delete"}, names(plan.ToDelete))
+	r.Equal([]string{"unchanged"}, names(plan.Unchanged))
+
+	// no writes are performed
+	r.Error(c.Get(ctx, client.ObjectKeyFromObject(desired[0]), &corev1.ConfigMap{}))
+
+	_, err = velaclient.PlanReconcile(ctx, c, append(desired, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "create"}, Data: map[string]string{"key": "value"}}), current)
+	r.Error(err)
+}
+
+func TestPlanReconcileWithServerDefaults(t *testing.T) {
+	desired := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example", Labels: map[string]string{"app": "example"}},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "example"}},
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "main", Image: "nginx:1"}},
+			}},
+		},
+	}
+	live := desired.DeepCopy()
+	live.ResourceVersion = "1"
+	live.Annotations = map[string]string{"deployment.kubernetes.io/revision": "1"}
+	live.Finalizers = []string{"example.com/finalizer"}
+	live.OwnerReferences = []metav1.OwnerReference{{APIVersion: "v1", Kind: "Secret", Name: "owner", UID: "uid"}}
+	live.Spec.Replicas = pointer.Int32(1)
+	live.Spec.RevisionHistoryLimit = pointer.Int32(10)
+	live.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyAlways
+	live.Spec.Template.Spec.Containers[0].ImagePullPolicy = corev1.PullIfNotPresent
+	live.Spec.Template.Spec.Containers[0].TerminationMessagePath = corev1.TerminationMessagePathDefault
+
+	r := require.New(t)
+	c := fake.NewClientBuilder().Build()
+	plan, err := velaclient.PlanReconcile(context.Background(), c, []client.Object{desired}, []client.Object{live})
+	r.NoError(err)
+	r.Len(plan.Unchanged, 1)
+	r.Empty(plan.ToUpdate)
+
+	changed := desired.DeepCopy()
+	changed.Spec.Template.Spec.Containers[0].Image = "nginx:2"
+	plan, err = velaclient.PlanReconcile(context.Background(), c, []client.Object{changed}, []client.Object{live})
+	r.NoError(err)
+	r.Len(plan.ToUpdate, 1)
+}
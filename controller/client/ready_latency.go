@@ -0,0 +1,170 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+)
+
+const (
+	// ControllerTimeToReadyKey metrics key for recording the time from object
+	// creation to being ready
+	ControllerTimeToReadyKey = "controller_time_to_ready_seconds"
+)
+
+var (
+	// controllerTimeToReady the time to ready metrics
+	controllerTimeToReady = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerTimeToReadyKey,
+			Help:      "time from object creation to the ready condition becoming true",
+			Buckets:   prometheus.ExponentialBuckets(0.5, 2, 14),
+		}, []string{"kind"})
+)
+
+var (
+	// ReadyLatencyRewatchBackoff the backoff between re-watches in
+	// WatchReadyLatency, which is reset once a watch receives events
+	ReadyLatencyRewatchBackoff = wait.Backoff{Duration: time.Second, Factor: 2, Jitter: 0.1, Steps: math.MaxInt32, Cap: time.Minute}
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerTimeToReady)
+}
+
+// WatchReadyLatency watches the objects of the kind and records the time from
+// their creation to the readyCondType condition becoming true. Each object is
+// observed at most once. Objects already ready when the watch starts are
+// skipped, and objects never becoming ready are not observed. The ready time
+// is the lastTransitionTime of the condition if set. Closed watches are
+// restarted with ReadyLatencyRewatchBackoff. It blocks until the context is
+// done.
+func WatchReadyLatency(ctx context.Context, c client.WithWatch, gvk schema.GroupVersionKind, readyCondType string) error {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err := c.List(ctx, list); err != nil {
+		return err
+	}
+	observed := map[types.UID]struct{}{}
+	for _, item := range list.Items {
+		if _, ready := getReadyTime(&item, readyCondType); ready {
+			observed[item.GetUID()] = struct{}{}
+		}
+	}
+	rv := list.GetResourceVersion()
+	backoff := ReadyLatencyRewatchBackoff
+	for {
+		w, err := c.Watch(ctx, list, &client.ListOptions{Raw: &metav1.ListOptions{ResourceVersion: rv}})
+		if err != nil {
+			return err
+		}
+		var received bool
+		if rv, received = observeReadyLatency(ctx, w, gvk.Kind, readyCondType, observed, rv); ctx.Err() != nil {
+			return nil
+		}
+		if received {
+			backoff = ReadyLatencyRewatchBackoff
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff.Step()):
+		}
+	}
+}
+
+// observeReadyLatency consumes the watch events until the watch closes or the
+// context is done, and returns the last seen resourceVersion and whether any
+// event is received
+func observeReadyLatency(ctx context.Context, w watch.Interface, kind string, readyCondType string, observed map[types.UID]struct{}, rv string) (string, bool) {
+	defer w.Stop()
+	received := false
+	for {
+		select {
+		case <-ctx.Done():
+			return rv, received
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return rv, received
+			}
+			if event.Type == watch.Error {
+				// the resourceVersion might be expired, restart from the latest
+				return "", received
+			}
+			received = true
+			obj, ok := event.Object.(*unstructured.Unstructured)
+			if !ok {
+				content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(event.Object)
+				if err != nil {
+					continue
+				}
+				obj = &unstructured.Unstructured{Object: content}
+			}
+			rv = obj.GetResourceVersion()
+			switch event.Type {
+			case watch.Deleted:
+				delete(observed, obj.GetUID())
+			case watch.Added, watch.Modified:
+				if _, found := observed[obj.GetUID()]; found {
+					continue
+				}
+				if readyTime, ready := getReadyTime(obj, readyCondType); ready {
+					observed[obj.GetUID()] = struct{}{}
+					controllerTimeToReady.WithLabelValues(kind).Observe(readyTime.Sub(obj.GetCreationTimestamp().Time).Seconds())
+				}
+			}
+		}
+	}
+}
+
+// getReadyTime returns the time the condition becomes true, and whether it
+// is true
+func getReadyTime(obj *unstructured.Unstructured, condType string) (time.Time, bool) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, item := range conditions {
+		cond, ok := item.(map[string]interface{})
+		if !ok || cond["type"] != condType {
+			continue
+		}
+		if cond["status"] != string(metav1.ConditionTrue) {
+			return time.Time{}, false
+		}
+		if s, ok := cond["lastTransitionTime"].(string); ok {
+			if t, err := time.Parse(time.RFC3339, s); err == nil {
+				return t, true
+			}
+		}
+		return time.Now(), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeWatchClient serves the watches from the watchers, and closed watches
+// once the watchers are exhausted
+type fakeWatchClient struct {
+	client.WithWatch
+	watchers chan watch.Interface
+	watches  int32
+}
+
+func (c *fakeWatchClient) Watch(context.Context, client.ObjectList, ...client.ListOption) (watch.Interface, error) {
+	atomic.AddInt32(&c.watches, 1)
+	select {
+	case w := <-c.watchers:
+		return w, nil
+	default:
+		w := watch.NewFake()
+		w.Stop()
+		return w, nil
+	}
+}
+
+func TestWatchReadyLatency(t *testing.T) {
+	r := require.New(t)
+	created := metav1.NewTime(time.Now().Add(-time.Minute).Truncate(time.Second))
+	w := watch.NewFake()
+	c := &fakeWatchClient{WithWatch: fake.NewClientBuilder().Build(), watchers: make(chan watch.Interface, 1)}
+	c.watchers <- w
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- WatchReadyLatency(ctx, c, corev1.SchemeGroupVersion.WithKind("Pod"), string(corev1.PodReady))
+	}()
+
+	m := &dto.Metric{}
+	r.NoError(controllerTimeToReady.WithLabelValues("Pod").(prometheus.Histogram).Write(m))
+	count, sum := m.Histogram.GetSampleCount(), m.Histogram.GetSampleSum()
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example", UID: "uid", CreationTimestamp: created}}
+	w.Add(pod.DeepCopy())
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(created.Add(30 * time.Second))}}
+	w.Modify(pod.DeepCopy())
+	// observed objects are not recorded again
+	w.Modify(pod.DeepCopy())
+	cancel()
+	r.NoError(<-done)
+	r.NoError(controllerTimeToReady.WithLabelValues("Pod").(prometheus.Histogram).Write(m))
+	r.Equal(count+1, m.Histogram.GetSampleCount())
+	r.Equal(30.0, m.Histogram.GetSampleSum()-sum)
+}
+
+func TestWatchReadyLatencyRewatchBackoff(t *testing.T) {
+	defer func(backoff wait.Backoff) { ReadyLatencyRewatchBackoff = backoff }(ReadyLatencyRewatchBackoff)
+	ReadyLatencyRewatchBackoff = wait.Backoff{Duration: 20 * time.Millisecond, Factor: 2, Steps: 10}
+	c := &fakeWatchClient{WithWatch: fake.NewClientBuilder().Build()}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	require.NoError(t, WatchReadyLatency(ctx, c, corev1.SchemeGroupVersion.WithKind("Pod"), string(corev1.PodReady)))
+	// re-watches at 0, 20ms, 60ms and 140ms
+	require.LessOrEqual(t, atomic.LoadInt32(&c.watches), int32(5))
+}
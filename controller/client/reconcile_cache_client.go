@@ -0,0 +1,214 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+	"github.com/kubevela/pkg/multicluster"
+)
+
+const (
+	// ControllerReconcileCacheHitsKey metrics key for counting the Gets served
+	// by the reconcile cache
+	ControllerReconcileCacheHitsKey = "controller_reconcile_cache_hits_total"
+)
+
+var (
+	// controllerReconcileCacheHits the reconcile cache hits metrics
+	controllerReconcileCacheHits = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerReconcileCacheHitsKey,
+			Help:      "number of gets served by the reconcile cache",
+		}, []string{"kind"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerReconcileCacheHits)
+}
+
+// reconcileCacheKey identifies the cached object. The object type is included
+// so that typed and unstructured Gets of the same resource do not mix up, and
+// the cluster is included so that the same key in different clusters does not.
+type reconcileCacheKey struct {
+	cluster string
+	gvk     schema.GroupVersionKind
+	key     client.ObjectKey
+	kind    reflect.Type
+}
+
+// clusterFrom returns the cluster of the request in the context, where the
+// local cluster is always empty
+func clusterFrom(ctx context.Context) string {
+	if cluster, _ := multicluster.ClusterFrom(ctx); !multicluster.IsLocal(cluster) {
+		return cluster
+	}
+	return ""
+}
+
+// reconcileCache the objects got during one reconcile
+type reconcileCache struct {
+	mu      sync.Mutex
+	objects map[reconcileCacheKey]client.Object
+}
+
+// WithReconcileCache attach a reconcile cache to the context. The Get results
+// of the ReconcileCacheClient using this context are memoized in the cache,
+// which is dropped together with the context when the reconcile ends.
+func WithReconcileCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, reconcileCacheContextKey, &reconcileCache{objects: map[reconcileCacheKey]client.Object{}})
+}
+
+func reconcileCacheFrom(ctx context.Context) *reconcileCache {
+	cache, _ := ctx.Value(reconcileCacheContextKey).(*reconcileCache)
+	return cache
+}
+
+// ReconcileCacheClient memoizes the Get results within one reconcile, if the
+// context is created by WithReconcileCache. Deep copies are returned so that
+// callers cannot mutate the cached objects. Writes to an object invalidate its
+// cached result.
+type ReconcileCacheClient struct {
+	client.Client
+}
+
+// NewReconcileCacheClient wrap client with reconcile-scoped Get cache
+func NewReconcileCacheClient(c client.Client) client.Client {
+	return &ReconcileCacheClient{Client: c}
+}
+
+func (c *ReconcileCacheClient) cacheKey(ctx context.Context, key client.ObjectKey, obj client.Object) (reconcileCacheKey, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return reconcileCacheKey{}, err
+	}
+	return reconcileCacheKey{cluster: clusterFrom(ctx), gvk: gvk, key: key, kind: reflect.TypeOf(obj)}, nil
+}
+
+func (c *ReconcileCacheClient) invalidate(ctx context.Context, obj client.Object) {
+	cache := reconcileCacheFrom(ctx)
+	if cache == nil {
+		return
+	}
+	key, err := c.cacheKey(ctx, client.ObjectKeyFromObject(obj), obj)
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if err != nil {
+		cache.objects = map[reconcileCacheKey]client.Object{}
+		return
+	}
+	for k := range cache.objects {
+		if k.cluster == key.cluster && k.gvk == key.gvk && k.key == key.key {
+			delete(cache.objects, k)
+		}
+	}
+}
+
+// Get resource from the reconcile cache, or from the client if not cached
+func (c *ReconcileCacheClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	cache := reconcileCacheFrom(ctx)
+	if cache == nil {
+		return c.Client.Get(ctx, key, obj)
+	}
+	_key, err := c.cacheKey(ctx, key, obj)
+	if err != nil {
+		return c.Client.Get(ctx, key, obj)
+	}
+	cache.mu.Lock()
+	cached, found := cache.objects[_key]
+	cache.mu.Unlock()
+	if found {
+		reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(cached.DeepCopyObject()).Elem())
+		controllerReconcileCacheHits.WithLabelValues(_key.gvk.Kind).Inc()
+		return nil
+	}
+	if err = c.Client.Get(ctx, key, obj); err != nil {
+		return err
+	}
+	cache.mu.Lock()
+	cache.objects[_key] = obj.DeepCopyObject().(client.Object)
+	cache.mu.Unlock()
+	return nil
+}
+
+// Create resource and invalidate its cached result
+func (c *ReconcileCacheClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	defer c.invalidate(ctx, obj)
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+// Delete resource and invalidate its cached result
+func (c *ReconcileCacheClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	defer c.invalidate(ctx, obj)
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+// Update resource and invalidate its cached result
+func (c *ReconcileCacheClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	defer c.invalidate(ctx, obj)
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+// Patch resource and invalidate its cached result
+func (c *ReconcileCacheClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	defer c.invalidate(ctx, obj)
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+// DeleteAllOf resources and drop all the cached results
+func (c *ReconcileCacheClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	if cache := reconcileCacheFrom(ctx); cache != nil {
+		defer func() {
+			cache.mu.Lock()
+			defer cache.mu.Unlock()
+			cache.objects = map[reconcileCacheKey]client.Object{}
+		}()
+	}
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+// Status returns the status writer which invalidates the cached results
+func (c *ReconcileCacheClient) Status() client.StatusWriter {
+	return &reconcileCacheStatusWriter{StatusWriter: c.Client.Status(), c: c}
+}
+
+type reconcileCacheStatusWriter struct {
+	client.StatusWriter
+	c *ReconcileCacheClient
+}
+
+// Update resource status and invalidate its cached result
+func (w *reconcileCacheStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	defer w.c.invalidate(ctx, obj)
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+// Patch resource status and invalidate its cached result
+func (w *reconcileCacheStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	defer w.c.invalidate(ctx, obj)
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
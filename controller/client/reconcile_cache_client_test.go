@@ -0,0 +1,106 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kubevela/pkg/multicluster"
+)
+
+type countingClient struct {
+	client.Client
+	gets int
+}
+
+func (c *countingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	c.gets++
+	return c.Client.Get(ctx, key, obj)
+}
+
+// clusterRoutingClient routes the requests to the client of the cluster in
+// the context, like the multicluster client does
+type clusterRoutingClient struct {
+	client.Client
+	clusters map[string]client.Client
+}
+
+func (c *clusterRoutingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	cluster, _ := multicluster.ClusterFrom(ctx)
+	return c.clusters[cluster].Get(ctx, key, obj)
+}
+
+func TestReconcileCacheClient(t *testing.T) {
+	r := require.New(t)
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example"}, Data: map[string]string{"key": "value"}}
+	cc := &countingClient{Client: fake.NewClientBuilder().WithObjects(cm).Build()}
+	c := NewReconcileCacheClient(cc)
+	key := client.ObjectKeyFromObject(cm)
+	hits := testutil.ToFloat64(controllerReconcileCacheHits.WithLabelValues("ConfigMap"))
+
+	// no reconcile cache in context
+	r.NoError(c.Get(context.Background(), key, &corev1.ConfigMap{}))
+	r.NoError(c.Get(context.Background(), key, &corev1.ConfigMap{}))
+	r.Equal(2, cc.gets)
+
+	ctx := WithReconcileCache(context.Background())
+	first := &corev1.ConfigMap{}
+	r.NoError(c.Get(ctx, key, first))
+	first.Data["key"] = "mutated"
+	second := &corev1.ConfigMap{}
+	r.NoError(c.Get(ctx, key, second))
+	r.Equal(3, cc.gets)
+	r.Equal("value", second.Data["key"])
+	r.Equal(hits+1, testutil.ToFloat64(controllerReconcileCacheHits.WithLabelValues("ConfigMap")))
+
+	// unstructured gets are cached separately
+	r.NoError(c.Get(ctx, key, &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap"}}))
+	r.Equal(4, cc.gets)
+
+	// writes invalidate the cached result
+	second.Data["key"] = "updated"
+	r.NoError(c.Update(ctx, second))
+	third := &corev1.ConfigMap{}
+	r.NoError(c.Get(ctx, key, third))
+	r.Equal(5, cc.gets)
+	r.Equal("updated", third.Data["key"])
+}
+
+func TestReconcileCacheClientMultiCluster(t *testing.T) {
+	r := require.New(t)
+	meta := metav1.ObjectMeta{Namespace: "default", Name: "example"}
+	c := NewReconcileCacheClient(&clusterRoutingClient{Client: fake.NewClientBuilder().Build(), clusters: map[string]client.Client{
+		"a": fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{ObjectMeta: meta, Data: map[string]string{"cluster": "a"}}).Build(),
+		"b": fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{ObjectMeta: meta, Data: map[string]string{"cluster": "b"}}).Build(),
+	}})
+	ctx := WithReconcileCache(context.Background())
+	key := client.ObjectKey{Namespace: "default", Name: "example"}
+	for _, cluster := range []string{"a", "b", "a", "b"} {
+		cm := &corev1.ConfigMap{}
+		r.NoError(c.Get(multicluster.WithCluster(ctx, cluster), key, cm))
+		r.Equal(cluster, cm.Data["cluster"])
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/utils/lru"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+	velaruntime "github.com/kubevela/pkg/util/runtime"
+)
+
+const (
+	// ControllerReconcileDedupSkippedKey metrics key for counting the
+	// reconciles skipped as the objects are unchanged since the last
+	// successful reconcile
+	ControllerReconcileDedupSkippedKey = "controller_reconcile_dedup_skipped_total"
+)
+
+var (
+	// controllerReconcileDedupSkipped the deduplicated reconciles metrics
+	controllerReconcileDedupSkipped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerReconcileDedupSkippedKey,
+			Help:      "number of reconciles skipped as the objects are unchanged since the last successful reconcile",
+		}, []string{"controller"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerReconcileDedupSkipped)
+}
+
+// ReconcileHashStore stores the hashes of objects at their last successful
+// reconciles, keyed by UID. The least recently used hashes are evicted when
+// the store is full.
+type ReconcileHashStore struct {
+	hashes *lru.Cache
+}
+
+// NewReconcileHashStore creates a ReconcileHashStore holding up to size hashes
+func NewReconcileHashStore(size int) *ReconcileHashStore {
+	return &ReconcileHashStore{hashes: lru.New(size)}
+}
+
+// Record stores the hash of the object, which should be called after the
+// object is reconciled successfully
+func (in *ReconcileHashStore) Record(obj client.Object) error {
+	hash, err := hashObjectSpec(obj)
+	if err != nil {
+		return err
+	}
+	in.hashes.Add(obj.GetUID(), hash)
+	return nil
+}
+
+// ShouldReconcile check if the object has changed since its last successful
+// reconcile recorded in the store. Only the fields compared by PlanReconcile
+// are hashed, such as spec, data, labels and annotations.
+// Objects not found in the store always need reconcile. The skipped
+// reconciles are counted under the controller set by velaruntime.WithController.
+func ShouldReconcile(ctx context.Context, obj client.Object, store *ReconcileHashStore) (bool, error) {
+	last, found := store.hashes.Get(obj.GetUID())
+	if !found {
+		return true, nil
+	}
+	hash, err := hashObjectSpec(obj)
+	if err != nil {
+		return false, err
+	}
+	if hash != last.(string) {
+		return true, nil
+	}
+	controller, _ := velaruntime.ControllerFrom(ctx)
+	controllerReconcileDedupSkipped.WithLabelValues(controller).Inc()
+	return false, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	velaruntime "github.com/kubevela/pkg/util/runtime"
+)
+
+func TestShouldReconcile(t *testing.T) {
+	r := require.New(t)
+	ctx := velaruntime.WithController(context.Background(), "dedup")
+	store := NewReconcileHashStore(1)
+	skipped := testutil.ToFloat64(controllerReconcileDedupSkipped.WithLabelValues("dedup"))
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example", UID: "uid-1", ResourceVersion: "1"},
+		Data:       map[string]string{"key": "value"},
+	}
+
+	should, err := ShouldReconcile(ctx, cm, store)
+	r.NoError(err)
+	r.True(should)
+	r.NoError(store.Record(cm))
+
+	// volatile metadata changes are ignored
+	cm.ResourceVersion = "2"
+	should, err = ShouldReconcile(ctx, cm, store)
+	r.NoError(err)
+	r.False(should)
+	r.Equal(skipped+1, testutil.ToFloat64(controllerReconcileDedupSkipped.WithLabelValues("dedup")))
+
+	cm.Data["key"] = "changed"
+	should, err = ShouldReconcile(ctx, cm, store)
+	r.NoError(err)
+	r.True(should)
+	r.NoError(store.Record(cm))
+
+	// the least recently used hash is evicted
+	other := cm.DeepCopy()
+	other.UID = "uid-2"
+	r.NoError(store.Record(other))
+	should, err = ShouldReconcile(ctx, cm, store)
+	r.NoError(err)
+	r.True(should)
+	r.Equal(skipped+1, testutil.ToFloat64(controllerReconcileDedupSkipped.WithLabelValues("dedup")))
+}
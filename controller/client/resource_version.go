@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/kubevela/pkg/util/k8s"
+)
+
+// PinnedResourceVersion pins the list request to the exact resourceVersion
+type PinnedResourceVersion string
+
+// ApplyToList applies this configuration to the given list options.
+func (rv PinnedResourceVersion) ApplyToList(opts *client.ListOptions) {
+	if opts.Raw == nil {
+		opts.Raw = &metav1.ListOptions{}
+	}
+	opts.Raw.ResourceVersion = string(rv)
+	opts.Raw.ResourceVersionMatch = metav1.ResourceVersionMatchExact
+}
+
+// GetAtRV gets the object at the exact resourceVersion, which can be used to
+// read a consistent snapshot of multiple objects. Since Get requests do not
+// support exact resourceVersion match, it is done through a List request
+// selecting the object name.
+// Note that the apiserver only keeps a limited window of history versions
+// (until etcd compaction, usually several minutes). Requesting a compacted
+// resourceVersion returns an Expired error (410 Gone), which can be checked
+// by kerrors.IsResourceExpired. Requests pinned to resourceVersion are always
+// sent to the apiserver, bypassing the cache.
+func GetAtRV(ctx context.Context, c client.Client, key client.ObjectKey, obj client.Object, resourceVersion string) error {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+	listGVK := gvk.GroupVersion().WithKind(gvk.Kind + "List")
+	var list client.ObjectList
+	if k8s.IsUnstructuredObject(obj) {
+		list = &unstructured.UnstructuredList{}
+		list.GetObjectKind().SetGroupVersionKind(listGVK)
+	} else {
+		_list, err := c.Scheme().New(listGVK)
+		if err != nil {
+			return err
+		}
+		if list, _ = _list.(client.ObjectList); list == nil {
+			return fmt.Errorf("%s is not a list type", listGVK)
+		}
+	}
+	if err = ListAtRV(ctx, c, list, resourceVersion,
+		client.InNamespace(key.Namespace),
+		client.MatchingFieldsSelector{Selector: fields.OneTermEqualSelector("metadata.name", key.Name)},
+	); err != nil {
+		return err
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if o, ok := item.(client.Object); ok && o.GetName() == key.Name && reflect.TypeOf(item) == reflect.TypeOf(obj) {
+			reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(item).Elem())
+			obj.GetObjectKind().SetGroupVersionKind(gvk)
+			return nil
+		}
+	}
+	mapping, err := c.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return err
+	}
+	return kerrors.NewNotFound(mapping.Resource.GroupResource(), key.Name)
+}
+
+// ListAtRV lists the objects at the exact resourceVersion. See GetAtRV for the
+// constraints of the apiserver.
+func ListAtRV(ctx context.Context, c client.Client, list client.ObjectList, resourceVersion string, opts ...client.ListOption) error {
+	ctx = withPinnedResourceVersion(ctx, resourceVersion)
+	return c.List(ctx, list, append(opts, PinnedResourceVersion(resourceVersion))...)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// listOptionsClient captures the list options and the pinned resourceVersion
+// in context of the last List request
+type listOptionsClient struct {
+	client.Client
+	opts   *client.ListOptions
+	pinned string
+}
+
+func (c *listOptionsClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	c.opts = (&client.ListOptions{}).ApplyOptions(opts)
+	c.pinned, _ = pinnedResourceVersionFrom(ctx)
+	return c.Client.List(ctx, list, client.InNamespace(c.opts.Namespace))
+}
+
+func TestGetAtRV(t *testing.T) {
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example"}, Data: map[string]string{"key": "value"}}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+	c := &listOptionsClient{Client: fake.NewClientBuilder().WithObjects(cm).WithRESTMapper(mapper).Build()}
+	ctx := context.Background()
+
+	obj := &corev1.ConfigMap{}
+	require.NoError(t, GetAtRV(ctx, c, client.ObjectKeyFromObject(cm), obj, "12"))
+	require.Equal(t, "value", obj.Data["key"])
+	require.Equal(t, "12", c.pinned)
+	require.Equal(t, "default", c.opts.Namespace)
+	require.Equal(t, "metadata.name=example", c.opts.FieldSelector.String())
+	require.Equal(t, "12", c.opts.Raw.ResourceVersion)
+	require.Equal(t, metav1.ResourceVersionMatchExact, c.opts.Raw.ResourceVersionMatch)
+
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("v1")
+	u.SetKind("ConfigMap")
+	require.NoError(t, GetAtRV(ctx, c, client.ObjectKeyFromObject(cm), u, "12"))
+	val, _, _ := unstructured.NestedString(u.Object, "data", "key")
+	require.Equal(t, "value", val)
+
+	err := GetAtRV(ctx, c, client.ObjectKey{Namespace: "default", Name: "none"}, &corev1.ConfigMap{}, "12")
+	require.True(t, kerrors.IsNotFound(err))
+	require.Equal(t, "configmaps", err.(kerrors.APIStatus).Status().Details.Kind)
+}
+
+func TestListAtRV(t *testing.T) {
+	c := &listOptionsClient{Client: fake.NewClientBuilder().Build()}
+	require.NoError(t, ListAtRV(context.Background(), c, &corev1.ConfigMapList{}, "34", client.InNamespace("vela-system")))
+	require.Equal(t, "34", c.pinned)
+	require.Equal(t, "vela-system", c.opts.Namespace)
+	require.Equal(t, "34", c.opts.Raw.ResourceVersion)
+	require.Equal(t, metav1.ResourceVersionMatchExact, c.opts.AsListOptions().ResourceVersionMatch)
+
+	reader := &delegatingReader{
+		CacheReader:  &slowCache{},
+		ClientReader: c,
+		scheme:       c.Scheme(),
+	}
+	c.pinned = ""
+	require.NoError(t, reader.List(withPinnedResourceVersion(context.Background(), "56"), &corev1.ConfigMapList{}))
+	require.Equal(t, "56", c.pinned)
+
+	// pinned requests are counted separately from the request latency
+	mc := &monitorClient{c}
+	pinned := testutil.ToFloat64(controllerClientPinnedRVRequests.WithLabelValues("", "List", "ConfigMap"))
+	require.NoError(t, ListAtRV(context.Background(), mc, &corev1.ConfigMapList{}, "78"))
+	require.Equal(t, pinned+1, testutil.ToFloat64(controllerClientPinnedRVRequests.WithLabelValues("", "List", "ConfigMap")))
+}
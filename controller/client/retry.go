@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+	"github.com/kubevela/pkg/util/k8s"
+	velaruntime "github.com/kubevela/pkg/util/runtime"
+)
+
+const (
+	// ControllerMutateRetryKey metrics key for counting retries caused by
+	// conflicts in MutateWithRetry
+	ControllerMutateRetryKey = "controller_mutate_retry_total"
+)
+
+var (
+	// controllerMutateRetry the conflict retry metrics of MutateWithRetry
+	controllerMutateRetry = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerMutateRetryKey,
+			Help:      "number of retries caused by conflicts when mutating objects",
+		}, []string{"controller", "kind"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerMutateRetry)
+}
+
+// MutateWithRetry gets the object of the given key, applies mutate on it and
+// updates it. If the update fails due to conflict, the object will be
+// re-fetched and the mutation will be retried with backoff.
+// T must be a pointer to the typed object struct, such as *corev1.ConfigMap.
+// Unstructured objects are not supported as the GVK cannot be inferred from
+// the type, which returns an error.
+func MutateWithRetry[T client.Object](ctx context.Context, c client.Client, key client.ObjectKey, mutate func(T) error, backoff wait.Backoff) error {
+	if k8s.IsUnstructuredObject(*new(T)) {
+		return fmt.Errorf("MutateWithRetry does not support unstructured objects")
+	}
+	attempts := 0
+	return retry.RetryOnConflict(backoff, func() error {
+		obj := reflect.New(reflect.TypeOf(*new(T)).Elem()).Interface().(T)
+		if attempts > 0 {
+			controllerMutateRetry.WithLabelValues(velaruntime.GetControllerInCaller(), k8s.GetKindForObject(obj, false)).Inc()
+		}
+		attempts++
+		if err := c.Get(ctx, key, obj); err != nil {
+			return err
+		}
+		if err := mutate(obj); err != nil {
+			return err
+		}
+		return c.Update(ctx, obj)
+	})
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type conflictClient struct {
+	client.Client
+	conflicts int
+}
+
+func (c *conflictClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if c.conflicts > 0 {
+		c.conflicts--
+		return kerrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, obj.GetName(), fmt.Errorf("injected"))
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func TestMutateWithRetry(t *testing.T) {
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example"}}
+	c := &conflictClient{Client: fake.NewClientBuilder().WithObjects(cm).Build(), conflicts: 1}
+	ctx := context.Background()
+	key := client.ObjectKeyFromObject(cm)
+	calls := 0
+	require.NoError(t, MutateWithRetry(ctx, c, key, func(obj *corev1.ConfigMap) error {
+		calls++
+		obj.Data = map[string]string{"key": "value"}
+		return nil
+	}, retry.DefaultRetry))
+	require.Equal(t, 2, calls)
+	require.Equal(t, 1.0, testutil.ToFloat64(controllerMutateRetry.WithLabelValues("", "ConfigMap")))
+	_cm := &corev1.ConfigMap{}
+	require.NoError(t, c.Get(ctx, key, _cm))
+	require.Equal(t, "value", _cm.Data["key"])
+
+	err := fmt.Errorf("mutate failed")
+	require.ErrorIs(t, MutateWithRetry(ctx, c, key, func(obj *corev1.ConfigMap) error { return err }, retry.DefaultRetry), err)
+	require.True(t, kerrors.IsNotFound(MutateWithRetry(ctx, c, client.ObjectKey{Name: "none"}, func(obj *corev1.ConfigMap) error { return nil }, retry.DefaultRetry)))
+	require.ErrorContains(t, MutateWithRetry(ctx, c, key, func(obj *unstructured.Unstructured) error { return nil }, retry.DefaultRetry), "does not support unstructured objects")
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+)
+
+const (
+	// ControllerRevisionSwapKey metrics key for counting the revision swaps
+	ControllerRevisionSwapKey = "controller_revision_swap_total"
+	// ControllerRevisionPrunedKey metrics key for counting the objects of old
+	// revisions pruned by revision swaps
+	ControllerRevisionPrunedKey = "controller_revision_pruned_total"
+)
+
+var (
+	// controllerRevisionSwap the revision swap metrics
+	controllerRevisionSwap = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerRevisionSwapKey,
+			Help:      "number of revision swaps of managed object sets",
+		}, []string{"result"})
+
+	// controllerRevisionPruned the pruned objects metrics
+	controllerRevisionPruned = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerRevisionPrunedKey,
+			Help:      "number of objects of old revisions pruned by revision swaps",
+		}, []string{"kind"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerRevisionSwap, controllerRevisionPruned)
+}
+
+// SwapRevision applies the new objects of the revision and then prunes the
+// objects of other revisions owned by the owner. The new objects are labeled
+// with the revision and owned by the owner. Pruning only starts after all the
+// new objects are applied, so that the old revision keeps serving if applying
+// fails. The kinds of the new objects and the managedKinds are checked for
+// pruning, so managedKinds should contain all the kinds the owner may manage,
+// including the ones dropped by the new revision. For namespaced owners, only
+// the objects in the owner namespace are checked, as owner references cannot
+// cross namespaces.
+func SwapRevision(ctx context.Context, c client.Client, owner client.Object, newObjs []client.Object, managedKinds []schema.GroupVersionKind, revision string, revisionLabel string) (err error) {
+	defer func() {
+		result := "success"
+		if err != nil {
+			result = "error"
+		}
+		controllerRevisionSwap.WithLabelValues(result).Inc()
+	}()
+	gvks := map[schema.GroupVersionKind]struct{}{}
+	for _, gvk := range managedKinds {
+		gvks[gvk] = struct{}{}
+	}
+	for _, obj := range newObjs {
+		gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+		if err != nil {
+			return err
+		}
+		gvks[gvk] = struct{}{}
+		objLabels := obj.GetLabels()
+		if objLabels == nil {
+			objLabels = map[string]string{}
+		}
+		objLabels[revisionLabel] = revision
+		obj.SetLabels(objLabels)
+		if err = controllerutil.SetOwnerReference(owner, obj, c.Scheme()); err != nil {
+			return err
+		}
+		if _, err = createOrPatch(ctx, c, obj); err != nil {
+			return fmt.Errorf("failed to apply %s %s of revision %s: %w", gvk.Kind, client.ObjectKeyFromObject(obj), revision, err)
+		}
+	}
+	exists, err := labels.NewRequirement(revisionLabel, selection.Exists, nil)
+	if err != nil {
+		return err
+	}
+	outdated, err := labels.NewRequirement(revisionLabel, selection.NotEquals, []string{revision})
+	if err != nil {
+		return err
+	}
+	selector := labels.NewSelector().Add(*exists, *outdated)
+	for gvk := range gvks {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err = c.List(ctx, list, client.InNamespace(owner.GetNamespace()), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return err
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if !isOwnedBy(obj, owner) {
+				continue
+			}
+			if err = c.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to prune %s %s of revision %s: %w", gvk.Kind, client.ObjectKeyFromObject(obj), obj.GetLabels()[revisionLabel], err)
+			}
+			controllerRevisionPruned.WithLabelValues(gvk.Kind).Inc()
+		}
+	}
+	return nil
+}
+
+func isOwnedBy(obj client.Object, owner client.Object) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == owner.GetUID() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	velaclient "github.com/kubevela/pkg/controller/client"
+)
+
+func TestSwapRevision(t *testing.T) {
+	const revisionLabel = "example.com/revision"
+	r := require.New(t)
+	owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "owner", UID: "owner-uid"}}
+	unowned := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "unowned", Labels: map[string]string{revisionLabel: "v1"}}}
+	// objects in other namespaces are not checked, even if they refer to the owner uid
+	otherNamespace := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace:       "other",
+		Name:            "other",
+		Labels:          map[string]string{revisionLabel: "v0"},
+		OwnerReferences: []metav1.OwnerReference{{APIVersion: "v1", Kind: "ConfigMap", Name: "owner", UID: "owner-uid"}},
+	}}
+	c := fake.NewClientBuilder().WithObjects(owner, unowned, otherNamespace).Build()
+	ctx := context.Background()
+	names := func() map[string]string {
+		cms := &corev1.ConfigMapList{}
+		r.NoError(c.List(ctx, cms, client.InNamespace("default")))
+		m := map[string]string{}
+		for _, cm := range cms.Items {
+			m[cm.Name] = cm.Labels[revisionLabel] + ":" + cm.Data["key"]
+		}
+		secrets := &corev1.SecretList{}
+		r.NoError(c.List(ctx, secrets, client.InNamespace("default")))
+		for _, secret := range secrets.Items {
+			m["secret/"+secret.Name] = secret.Labels[revisionLabel]
+		}
+		return m
+	}
+	managedKinds := []schema.GroupVersionKind{corev1.SchemeGroupVersion.WithKind("ConfigMap"), corev1.SchemeGroupVersion.WithKind("Secret")}
+
+	r.NoError(velaclient.SwapRevision(ctx, c, owner, []client.Object{
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a"}, Data: map[string]string{"key": "1"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "b"}, Data: map[string]string{"key": "1"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "s"}},
+	}, managedKinds, "v1", revisionLabel))
+	r.Equal(map[string]string{"owner": ":", "unowned": "v1:", "a": "v1:1", "b": "v1:1", "secret/s": "v1"}, names())
+
+	// kinds dropped by the new revision are pruned as well
+	r.NoError(velaclient.SwapRevision(ctx, c, owner, []client.Object{
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "b"}, Data: map[string]string{"key": "2"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "c"}, Data: map[string]string{"key": "2"}},
+	}, managedKinds, "v2", revisionLabel))
+	r.Equal(map[string]string{"owner": ":", "unowned": "v1:", "b": "v2:2", "c": "v2:2"}, names())
+	r.NoError(c.Get(ctx, client.ObjectKeyFromObject(otherNamespace), &corev1.ConfigMap{}))
+}
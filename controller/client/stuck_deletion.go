@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+	"github.com/kubevela/pkg/util/k8s"
+)
+
+const (
+	// ControllerStuckDeletionsKey metrics key for recording the number of
+	// objects stuck in deletion
+	ControllerStuckDeletionsKey = "controller_stuck_deletions"
+)
+
+var (
+	// controllerStuckDeletions the stuck deletions metrics
+	controllerStuckDeletions = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerStuckDeletionsKey,
+			Help:      "number of objects stuck in deletion by finalizers, found by the last check",
+		}, []string{"kind", "apiVersion", "namespace"})
+)
+
+// stuckDeletionNamespaces the namespaces having stuck deletions in the
+// gauge for each kind, so that the series can be removed once resolved
+var stuckDeletionNamespaces = struct {
+	sync.Mutex
+	m map[schema.GroupVersionKind]map[string]struct{}
+}{m: map[schema.GroupVersionKind]map[string]struct{}{}}
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerStuckDeletions)
+}
+
+// FindStuckDeletions lists the objects of the kind in the namespace which have
+// been deleted for longer than olderThan but are still kept by finalizers.
+// An empty namespace means all namespaces. The number of found objects in each
+// namespace is exposed by the stuck deletions gauge, and the namespaces with
+// no stuck deletion in the checked scope are removed from the gauge.
+func FindStuckDeletions(ctx context.Context, c client.Client, gvk schema.GroupVersionKind, namespace string, olderThan time.Duration) ([]client.Object, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	var stuck []client.Object
+	counts := map[string]int{}
+	for i := range list.Items {
+		obj := &list.Items[i]
+		ts := obj.GetDeletionTimestamp()
+		if ts == nil || len(obj.GetFinalizers()) == 0 || time.Since(ts.Time) < olderThan {
+			continue
+		}
+		stuck = append(stuck, obj)
+		counts[obj.GetNamespace()]++
+	}
+	recordStuckDeletions(gvk, namespace, counts)
+	return stuck, nil
+}
+
+// recordStuckDeletions sets the gauge for the namespaces with stuck deletions,
+// and deletes the series of the other namespaces in the checked scope
+func recordStuckDeletions(gvk schema.GroupVersionKind, namespace string, counts map[string]int) {
+	apiVersion := k8s.NormalizeAPIVersion(gvk.GroupVersion().String())
+	stuckDeletionNamespaces.Lock()
+	defer stuckDeletionNamespaces.Unlock()
+	recorded := stuckDeletionNamespaces.m[gvk]
+	if recorded == nil {
+		recorded = map[string]struct{}{}
+		stuckDeletionNamespaces.m[gvk] = recorded
+	}
+	for ns := range recorded {
+		if _, found := counts[ns]; !found && (namespace == "" || namespace == ns) {
+			controllerStuckDeletions.DeleteLabelValues(gvk.Kind, apiVersion, ns)
+			delete(recorded, ns)
+		}
+	}
+	for ns, cnt := range counts {
+		controllerStuckDeletions.WithLabelValues(gvk.Kind, apiVersion, ns).Set(float64(cnt))
+		recorded[ns] = struct{}{}
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestFindStuckDeletions(t *testing.T) {
+	r := require.New(t)
+	deletedAt := metav1.NewTime(time.Now().Add(-time.Hour))
+	c := fake.NewClientBuilder().WithObjects(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "stuck", DeletionTimestamp: &deletedAt, Finalizers: []string{"example.com/finalizer"}}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "normal"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "vela-system", Name: "stuck", DeletionTimestamp: &deletedAt, Finalizers: []string{"example.com/finalizer"}}},
+	).Build()
+	stuck, err := FindStuckDeletions(context.Background(), c, corev1.SchemeGroupVersion.WithKind("ConfigMap"), "default", 10*time.Minute)
+	r.NoError(err)
+	r.Len(stuck, 1)
+	r.Equal("stuck", stuck[0].GetName())
+	r.Equal(1.0, testutil.ToFloat64(controllerStuckDeletions.WithLabelValues("ConfigMap", "v1", "default")))
+	r.Equal(1, testutil.CollectAndCount(controllerStuckDeletions))
+
+	stuck, err = FindStuckDeletions(context.Background(), c, corev1.SchemeGroupVersion.WithKind("ConfigMap"), "", 10*time.Minute)
+	r.NoError(err)
+	r.Len(stuck, 2)
+	r.Equal(1.0, testutil.ToFloat64(controllerStuckDeletions.WithLabelValues("ConfigMap", "v1", "vela-system")))
+	r.Equal(2, testutil.CollectAndCount(controllerStuckDeletions))
+
+	// resolved namespaces are removed from the gauge
+	stuck, err = FindStuckDeletions(context.Background(), c, corev1.SchemeGroupVersion.WithKind("ConfigMap"), "default", 2*time.Hour)
+	r.NoError(err)
+	r.Empty(stuck)
+	r.Equal(1, testutil.CollectAndCount(controllerStuckDeletions))
+	_, err = FindStuckDeletions(context.Background(), c, corev1.SchemeGroupVersion.WithKind("ConfigMap"), "", 2*time.Hour)
+	r.NoError(err)
+	r.Equal(0, testutil.CollectAndCount(controllerStuckDeletions))
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+	"github.com/kubevela/pkg/util/k8s"
+)
+
+const (
+	// ControllerTopoApplyKey metrics key for counting the objects applied in
+	// dependency order by topological apply
+	ControllerTopoApplyKey = "controller_topo_apply_total"
+)
+
+var (
+	// controllerTopoApply the topological apply metrics
+	controllerTopoApply = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerTopoApplyKey,
+			Help:      "number of objects applied in dependency order by topological apply",
+		}, []string{"kind", "result"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerTopoApply)
+}
+
+// TopoApply applies the objects after their dependencies. The dependencies of
+// each object are returned by depExtractor as object keys, which refer to all
+// the objects in objs with the key regardless of their kinds, except the
+// dependent object itself. Dependencies not in objs are treated as already
+// existing. Among the objects whose dependencies are all
+// applied, the first one in objs is applied next. If the dependencies form a cycle, an
+// error describing the cycle is returned before applying any object. Objects
+// are created or patched one by one, and the first failure stops the apply,
+// as the remaining objects may depend on the failed one.
+func TopoApply(ctx context.Context, c client.Client, objs []client.Object, depExtractor func(client.Object) []client.ObjectKey) error {
+	order, err := sortByDependency(c.Scheme(), objs, depExtractor)
+	if err != nil {
+		return err
+	}
+	for _, obj := range order {
+		kind := k8s.GetKindForObject(obj, true)
+		if _, err = createOrPatch(ctx, c, obj); err != nil {
+			controllerTopoApply.WithLabelValues(kind, "error").Inc()
+			return fmt.Errorf("failed to apply %s %s: %w", k8s.GetKindForObject(obj, false), client.ObjectKeyFromObject(obj), err)
+		}
+		controllerTopoApply.WithLabelValues(kind, "success").Inc()
+	}
+	return nil
+}
+
+// topoNodeKey identifies the object in the dependency graph
+type topoNodeKey struct {
+	gk  schema.GroupKind
+	key client.ObjectKey
+}
+
+// sortByDependency sorts the objects topologically with Kahn's algorithm,
+// always picking the first ready object in the original order
+func sortByDependency(scheme *runtime.Scheme, objs []client.Object, depExtractor func(client.Object) []client.ObjectKey) ([]client.Object, error) {
+	nodes := make(map[topoNodeKey]bool, len(objs))
+	index := make(map[client.ObjectKey][]int, len(objs))
+	for i, obj := range objs {
+		gvk, err := apiutil.GVKForObject(obj, scheme)
+		if err != nil {
+			return nil, err
+		}
+		key := client.ObjectKeyFromObject(obj)
+		node := topoNodeKey{gk: gvk.GroupKind(), key: key}
+		if nodes[node] {
+			return nil, fmt.Errorf("duplicated object %s %s", gvk.Kind, key)
+		}
+		nodes[node] = true
+		index[key] = append(index[key], i)
+	}
+	deps := make([][]int, len(objs))
+	dependents := make([][]int, len(objs))
+	pending := make([]int, len(objs))
+	for i, obj := range objs {
+		seen := map[int]bool{i: true}
+		for _, key := range depExtractor(obj) {
+			for _, j := range index[key] {
+				if seen[j] {
+					continue
+				}
+				seen[j] = true
+				deps[i] = append(deps[i], j)
+				dependents[j] = append(dependents[j], i)
+				pending[i]++
+			}
+		}
+	}
+	applied := make([]bool, len(objs))
+	order := make([]client.Object, 0, len(objs))
+	for next := 0; next < len(objs); {
+		if applied[next] || pending[next] > 0 {
+			next++
+			continue
+		}
+		applied[next] = true
+		order = append(order, objs[next])
+		for _, j := range dependents[next] {
+			pending[j]--
+		}
+		next = 0
+	}
+	if len(order) == len(objs) {
+		return order, nil
+	}
+	return nil, findDependencyCycle(objs, deps, applied)
+}
+
+// findDependencyCycle follows the dependencies among the unsorted objects,
+// each of which has at least one unsorted dependency, until an object is
+// visited twice
+func findDependencyCycle(objs []client.Object, deps [][]int, sorted []bool) error {
+	cur := 0
+	for sorted[cur] {
+		cur++
+	}
+	visited := map[int]int{}
+	var path []int
+	for {
+		if pos, found := visited[cur]; found {
+			path = append(path[pos:], cur)
+			break
+		}
+		visited[cur] = len(path)
+		path = append(path, cur)
+		for _, j := range deps[cur] {
+			if !sorted[j] {
+				cur = j
+				break
+			}
+		}
+	}
+	names := make([]string, 0, len(path))
+	for _, i := range path {
+		names = append(names, fmt.Sprintf("%s %s", k8s.GetKindForObject(objs[i], false), client.ObjectKeyFromObject(objs[i])))
+	}
+	return fmt.Errorf("dependency cycle detected: %s", strings.Join(names, " -> "))
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kubevela/pkg/util/k8s"
+)
+
+// orderRecordingClient records the names and kinds of the created objects
+type orderRecordingClient struct {
+	client.Client
+	created []string
+	kinds   []string
+}
+
+func (c *orderRecordingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.created = append(c.created, obj.GetName())
+	c.kinds = append(c.kinds, k8s.GetKindForObject(obj, false))
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func TestTopoApply(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	depExtractor := func(obj client.Object) []client.ObjectKey {
+		var keys []client.ObjectKey
+		for _, name := range strings.Split(obj.GetAnnotations()["depends-on"], ",") {
+			if name != "" {
+				keys = append(keys, client.ObjectKey{Namespace: obj.GetNamespace(), Name: name})
+			}
+		}
+		return keys
+	}
+
+	succeeded := testutil.ToFloat64(controllerTopoApply.WithLabelValues("ConfigMap", "success"))
+	c := &orderRecordingClient{Client: fake.NewClientBuilder().Build()}
+	objs := []client.Object{
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", Annotations: map[string]string{"depends-on": "config,db"}}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "db", Annotations: map[string]string{"depends-on": "config,external"}}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "config"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "monitor"}},
+	}
+	r.NoError(TopoApply(ctx, c, objs, depExtractor))
+	r.Equal([]string{"config", "db", "app", "monitor"}, c.created)
+	r.Equal(succeeded+4, testutil.ToFloat64(controllerTopoApply.WithLabelValues("ConfigMap", "success")))
+
+	// cyclic dependencies are rejected before applying
+	c = &orderRecordingClient{Client: fake.NewClientBuilder().Build()}
+	objs = []client.Object{
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "standalone"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a", Annotations: map[string]string{"depends-on": "b"}}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "b", Annotations: map[string]string{"depends-on": "c"}}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "c", Annotations: map[string]string{"depends-on": "a"}}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "d", Annotations: map[string]string{"depends-on": "a"}}},
+	}
+	err := TopoApply(ctx, c, objs, depExtractor)
+	r.ErrorContains(err, "dependency cycle detected: ConfigMap default/a -> ConfigMap default/b -> ConfigMap default/c -> ConfigMap default/a")
+	r.Empty(c.created)
+
+	objs = []client.Object{
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a"}},
+	}
+	err = TopoApply(ctx, c, objs, depExtractor)
+	r.ErrorContains(err, "duplicated object ConfigMap default/a")
+
+	// objects of different kinds can share the key, and dependencies refer
+	// to all of them
+	c = &orderRecordingClient{Client: fake.NewClientBuilder().Build()}
+	objs = []client.Object{
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", Annotations: map[string]string{"depends-on": "app,db"}}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", Annotations: map[string]string{"depends-on": "db"}}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "db"}},
+	}
+	r.NoError(TopoApply(ctx, c, objs, depExtractor))
+	r.Equal([]string{"db", "app", "app"}, c.created)
+	r.Equal([]string{"Secret", "Secret", "ConfigMap"}, c.kinds)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+	"github.com/kubevela/pkg/util/k8s"
+)
+
+const (
+	// ControllerObjectTransformKey metrics key for counting the transforms
+	// applied to objects before write
+	ControllerObjectTransformKey = "controller_object_transform_total"
+)
+
+var (
+	// controllerObjectTransform the object transform metrics
+	controllerObjectTransform = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerObjectTransformKey,
+			Help:      "number of transforms applied to objects before write",
+		}, []string{"transform", "result"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerObjectTransform)
+}
+
+// ObjectTransform mutates the object before it is written
+type ObjectTransform func(client.Object) error
+
+// TransformingClient applies the transforms in order to the objects passed to
+// Create, Update and Patch (including server-side apply). If any transform
+// fails, the write is aborted.
+type TransformingClient struct {
+	client.Client
+	transforms []ObjectTransform
+	names      []string
+}
+
+// NewTransformingClient wrap client with transforms applied before write. The
+// transforms are recorded in metrics by their function names.
+func NewTransformingClient(c client.Client, transforms []ObjectTransform) client.Client {
+	names := make([]string, len(transforms))
+	for i, transform := range transforms {
+		names[i] = runtime.FuncForPC(reflect.ValueOf(transform).Pointer()).Name()
+	}
+	return &TransformingClient{Client: c, transforms: transforms, names: names}
+}
+
+func (c *TransformingClient) transform(obj client.Object) error {
+	for i, transform := range c.transforms {
+		if err := transform(obj); err != nil {
+			controllerObjectTransform.WithLabelValues(c.names[i], "error").Inc()
+			return fmt.Errorf("transform %s failed for %s %s: %w", c.names[i], k8s.GetKindForObject(obj, false), client.ObjectKeyFromObject(obj), err)
+		}
+		controllerObjectTransform.WithLabelValues(c.names[i], "success").Inc()
+	}
+	return nil
+}
+
+// Create resource after transforms
+func (c *TransformingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := c.transform(obj); err != nil {
+		return err
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+// Update resource after transforms
+func (c *TransformingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if err := c.transform(obj); err != nil {
+		return err
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+// Patch resource after transforms. The transforms take effect for patches
+// built from the object, such as client.MergeFrom and client.Apply.
+func (c *TransformingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if err := c.transform(obj); err != nil {
+		return err
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func addTeamLabel(obj client.Object) error {
+	if obj.GetLabels() == nil {
+		obj.SetLabels(map[string]string{})
+	}
+	obj.GetLabels()["team"] = "vela"
+	return nil
+}
+
+func addOwnerAnnotation(obj client.Object) error {
+	team, found := obj.GetLabels()["team"]
+	if !found {
+		return fmt.Errorf("team label not set")
+	}
+	obj.SetAnnotations(map[string]string{"owner": team + "-admin"})
+	return nil
+}
+
+func TestTransformingClient(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	base := fake.NewClientBuilder().Build()
+	c := NewTransformingClient(base, []ObjectTransform{addTeamLabel, addOwnerAnnotation})
+	labelSuccess := testutil.ToFloat64(controllerObjectTransform.WithLabelValues("github.com/kubevela/pkg/controller/client.addTeamLabel", "success"))
+	annotationSuccess := testutil.ToFloat64(controllerObjectTransform.WithLabelValues("github.com/kubevela/pkg/controller/client.addOwnerAnnotation", "success"))
+	annotationError := testutil.ToFloat64(controllerObjectTransform.WithLabelValues("github.com/kubevela/pkg/controller/client.addOwnerAnnotation", "error"))
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example"}}
+	r.NoError(c.Create(ctx, cm))
+	r.NoError(base.Get(ctx, client.ObjectKeyFromObject(cm), cm))
+	r.Equal("vela", cm.Labels["team"])
+	r.Equal("vela-admin", cm.Annotations["owner"])
+	r.Equal(labelSuccess+1, testutil.ToFloat64(controllerObjectTransform.WithLabelValues("github.com/kubevela/pkg/controller/client.addTeamLabel", "success")))
+	r.Equal(annotationSuccess+1, testutil.ToFloat64(controllerObjectTransform.WithLabelValues("github.com/kubevela/pkg/controller/client.addOwnerAnnotation", "success")))
+
+	// failed transform aborts the write
+	c = NewTransformingClient(base, []ObjectTransform{addOwnerAnnotation})
+	cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "failed"}}
+	r.Error(c.Create(ctx, cm))
+	r.Error(base.Get(ctx, client.ObjectKeyFromObject(cm), cm))
+	r.Equal(annotationError+1, testutil.ToFloat64(controllerObjectTransform.WithLabelValues("github.com/kubevela/pkg/controller/client.addOwnerAnnotation", "error")))
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+	"github.com/kubevela/pkg/util/k8s"
+)
+
+const (
+	// ControllerValidationFailuresKey metrics key for counting the writes
+	// rejected by client-side validation
+	ControllerValidationFailuresKey = "controller_validation_failures_total"
+)
+
+var (
+	// controllerValidationFailures the client-side validation failures metrics
+	controllerValidationFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerValidationFailuresKey,
+			Help:      "number of writes rejected by client-side validation",
+		}, []string{"kind"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerValidationFailures)
+}
+
+// ObjectValidator validates the object before it is written
+type ObjectValidator func(client.Object) field.ErrorList
+
+// ValidatingClient runs the validators against the objects passed to Create,
+// Update and Patch (including server-side apply). If any validator reports
+// errors, the write is aborted with the errors of all validators aggregated.
+// It works as a defense-in-depth for the validating webhooks, which might
+// fail open when misconfigured.
+type ValidatingClient struct {
+	client.Client
+	validators []ObjectValidator
+}
+
+// NewValidatingClient wrap client with validators running before write
+func NewValidatingClient(c client.Client, validators []ObjectValidator) client.Client {
+	return &ValidatingClient{Client: c, validators: validators}
+}
+
+func (c *ValidatingClient) validate(obj client.Object) error {
+	var errs field.ErrorList
+	for _, validator := range c.validators {
+		errs = append(errs, validator(obj)...)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	kind := k8s.GetKindForObject(obj, false)
+	controllerValidationFailures.WithLabelValues(kind).Inc()
+	return fmt.Errorf("validation failed for %s %s: %w", kind, client.ObjectKeyFromObject(obj), errs.ToAggregate())
+}
+
+// Create resource after validation
+func (c *ValidatingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := c.validate(obj); err != nil {
+		return err
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+// Update resource after validation
+func (c *ValidatingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if err := c.validate(obj); err != nil {
+		return err
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+// Patch resource after validation. The validators run against the object
+// passed in, which is expected to be the desired state, as with
+// client.MergeFrom and client.Apply.
+func (c *ValidatingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if err := c.validate(obj); err != nil {
+		return err
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
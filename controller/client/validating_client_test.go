@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func requireTeamLabel(obj client.Object) field.ErrorList {
+	if _, found := obj.GetLabels()["team"]; !found {
+		return field.ErrorList{field.Required(field.NewPath("metadata", "labels", "team"), "team label is required")}
+	}
+	return nil
+}
+
+func forbidPrivilegedName(obj client.Object) field.ErrorList {
+	if obj.GetName() == "privileged" {
+		return field.ErrorList{field.Forbidden(field.NewPath("metadata", "name"), "privileged name is reserved")}
+	}
+	return nil
+}
+
+func TestValidatingClient(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	base := fake.NewClientBuilder().Build()
+	c := NewValidatingClient(base, []ObjectValidator{requireTeamLabel, forbidPrivilegedName})
+	failures := testutil.ToFloat64(controllerValidationFailures.WithLabelValues("ConfigMap"))
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "privileged"}}
+	err := c.Create(ctx, cm)
+	r.ErrorContains(err, "metadata.labels.team")
+	r.ErrorContains(err, "metadata.name")
+	r.Error(base.Get(ctx, client.ObjectKeyFromObject(cm), cm))
+	r.Equal(failures+1, testutil.ToFloat64(controllerValidationFailures.WithLabelValues("ConfigMap")))
+
+	cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example", Labels: map[string]string{"team": "vela"}}}
+	r.NoError(c.Create(ctx, cm))
+	cm.Labels = nil
+	r.ErrorContains(c.Update(ctx, cm), "metadata.labels.team")
+	r.Equal(failures+2, testutil.ToFloat64(controllerValidationFailures.WithLabelValues("ConfigMap")))
+	r.NoError(base.Get(ctx, client.ObjectKeyFromObject(cm), cm))
+	r.Equal("vela", cm.Labels["team"])
+}
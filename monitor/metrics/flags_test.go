@@ -0,0 +1,33 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlags(t *testing.T) {
+	set := pflag.NewFlagSet("test", pflag.PanicOnError)
+	AddFlags(set)
+	require.False(t, IsVerbose())
+	require.NoError(t, set.Parse([]string{"--metrics-detail-level=1"}))
+	require.True(t, IsVerbose())
+	MetricsDetailLevel = DetailLevelDefault
+}
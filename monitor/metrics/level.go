@@ -0,0 +1,38 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+// DetailLevel the level of details for recording metrics
+type DetailLevel int
+
+const (
+	// DetailLevelDefault only records the metrics which are cheap to collect
+	DetailLevelDefault DetailLevel = iota
+	// DetailLevelVerbose records additional metrics which are costly to
+	// collect, such as the ones requiring object serialization
+	DetailLevelVerbose
+)
+
+var (
+	// MetricsDetailLevel the detail level for recording metrics
+	MetricsDetailLevel = DetailLevelDefault
+)
+
+// IsVerbose check if verbose metrics should be recorded
+func IsVerbose() bool {
+	return MetricsDetailLevel >= DetailLevelVerbose
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"k8s.io/klog/v2"
+)
+
+// OTelInstrumentationName the instrumentation name of the OTel meter
+const OTelInstrumentationName = "github.com/kubevela/pkg"
+
+// OTelHistogram mirrors a histogram metric as an OTel value recorder. It
+// records nothing until an OTel meter provider is registered by
+// RegisterOTelMeterProvider, so Prometheus users are unaffected.
+type OTelHistogram struct {
+	name        string
+	description string
+	recorder    atomic.Value
+}
+
+var otelRegistry = struct {
+	sync.Mutex
+	histograms []*OTelHistogram
+	meter      *metric.Meter
+}{}
+
+// NewOTelHistogram creates an OTel histogram, the name is prefixed with the
+// KubeVelaSubsystem in the same way as the Prometheus metrics. If an OTel
+// meter provider is registered but fails to create the instrument, the error
+// is logged and the histogram records nothing.
+func NewOTelHistogram(name string, description string) *OTelHistogram {
+	h := &OTelHistogram{name: KubeVelaSubsystem + "_" + name, description: description}
+	otelRegistry.Lock()
+	defer otelRegistry.Unlock()
+	otelRegistry.histograms = append(otelRegistry.histograms, h)
+	if otelRegistry.meter != nil {
+		recorder, err := h.newRecorder(*otelRegistry.meter)
+		if err != nil {
+			klog.Errorf("failed to create OTel instrument for histogram %s: %v", h.name, err)
+			return h
+		}
+		h.recorder.Store(recorder)
+	}
+	return h
+}
+
+func (in *OTelHistogram) newRecorder(meter metric.Meter) (metric.Float64ValueRecorder, error) {
+	return meter.NewFloat64ValueRecorder(in.name, metric.WithDescription(in.description))
+}
+
+// Record the value with the labels if an OTel meter provider is registered
+func (in *OTelHistogram) Record(ctx context.Context, value float64, labels ...attribute.KeyValue) {
+	if recorder, ok := in.recorder.Load().(metric.Float64ValueRecorder); ok {
+		recorder.Record(ctx, value, labels...)
+	}
+}
+
+// RegisterOTelMeterProvider creates the OTel instruments for all the
+// histograms created by NewOTelHistogram from the meter provider. It is
+// independent of the Prometheus registry, which can be used alone. Registering
+// again replaces the instruments of the previous meter provider. If any of the
+// instruments fails to be created, the error is returned and the previous
+// instruments are kept.
+func RegisterOTelMeterProvider(provider metric.MeterProvider) error {
+	meter := provider.Meter(OTelInstrumentationName)
+	otelRegistry.Lock()
+	defer otelRegistry.Unlock()
+	recorders := make([]metric.Float64ValueRecorder, len(otelRegistry.histograms))
+	for i, h := range otelRegistry.histograms {
+		recorder, err := h.newRecorder(meter)
+		if err != nil {
+			return fmt.Errorf("failed to create OTel instrument for histogram %s: %w", h.name, err)
+		}
+		recorders[i] = recorder
+	}
+	for i, h := range otelRegistry.histograms {
+		h.recorder.Store(recorders[i])
+	}
+	otelRegistry.meter = &meter
+	return nil
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	export "go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+	processor "go.opentelemetry.io/otel/sdk/metric/processor/basic"
+	"go.opentelemetry.io/otel/sdk/metric/selector/simple"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+	velaruntime "github.com/kubevela/pkg/util/runtime"
+)
+
+func TestOTelHistogram(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	before := metrics.NewOTelHistogram("test_otel_before_seconds", "test histogram")
+	before.Record(ctx, 1, attribute.String("controller", "app"))
+
+	reader := controller.New(processor.New(simple.NewWithInexpensiveDistribution(), export.CumulativeExportKindSelector()))
+	r.NoError(metrics.RegisterOTelMeterProvider(reader.MeterProvider()))
+	after := metrics.NewOTelHistogram("test_otel_after_seconds", "test histogram")
+	before.Record(ctx, 0.5, attribute.String("controller", "app"))
+	after.Record(ctx, 1.5, attribute.String("controller", "app"))
+	after.Record(ctx, 2.5, attribute.String("controller", "app"))
+	_, err := velaruntime.MonitorReconcile("test-otel", reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+		return reconcile.Result{}, nil
+	})).Reconcile(ctx, reconcile.Request{})
+	r.NoError(err)
+
+	r.NoError(reader.Collect(ctx))
+	counts, sums := map[string]uint64{}, map[string]float64{}
+	r.NoError(reader.ForEach(export.CumulativeExportKindSelector(), func(record export.Record) error {
+		agg := record.Aggregation().(aggregation.MinMaxSumCount)
+		cnt, err := agg.Count()
+		if err != nil {
+			return err
+		}
+		sum, err := agg.Sum()
+		if err != nil {
+			return err
+		}
+		name := record.Descriptor().Name()
+		if controller, ok := record.Labels().Value("controller"); ok {
+			name += "/" + controller.AsString()
+		}
+		counts[name], sums[name] = cnt, sum.AsFloat64()
+		return nil
+	}))
+	r.Equal(uint64(1), counts["kubevela_test_otel_before_seconds/app"])
+	r.Equal(0.5, sums["kubevela_test_otel_before_seconds/app"])
+	r.Equal(uint64(2), counts["kubevela_test_otel_after_seconds/app"])
+	r.Equal(4.0, sums["kubevela_test_otel_after_seconds/app"])
+	r.Equal(uint64(1), counts["kubevela_controller_reconcile_time_seconds/test-otel"])
+}
+
+// failingMeterImpl fails to create the instrument of the given name
+type failingMeterImpl struct {
+	metric.MeterImpl
+	name string
+}
+
+func (in failingMeterImpl) NewSyncInstrument(descriptor metric.Descriptor) (metric.SyncImpl, error) {
+	if descriptor.Name() == in.name {
+		return nil, fmt.Errorf("instrument %s not supported", in.name)
+	}
+	return in.MeterImpl.NewSyncInstrument(descriptor)
+}
+
+type failingMeterProvider struct {
+	metric.MeterProvider
+	name string
+}
+
+func (in failingMeterProvider) Meter(name string, opts ...metric.MeterOption) metric.Meter {
+	impl := failingMeterImpl{MeterImpl: in.MeterProvider.Meter(name, opts...).MeterImpl(), name: in.name}
+	return metric.WrapMeterImpl(impl, name, opts...)
+}
+
+func TestRegisterOTelMeterProviderFailure(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	reader := controller.New(processor.New(simple.NewWithInexpensiveDistribution(), export.CumulativeExportKindSelector()))
+	r.NoError(metrics.RegisterOTelMeterProvider(reader.MeterProvider()))
+	h := metrics.NewOTelHistogram("test_otel_kept_seconds", "test histogram")
+
+	// instruments of the previous meter provider are kept if any fails
+	failing := controller.New(processor.New(simple.NewWithInexpensiveDistribution(), export.CumulativeExportKindSelector()))
+	err := metrics.RegisterOTelMeterProvider(failingMeterProvider{MeterProvider: failing.MeterProvider(), name: "kubevela_test_otel_kept_seconds"})
+	r.ErrorContains(err, "instrument kubevela_test_otel_kept_seconds not supported")
+	h.Record(ctx, 1)
+	metrics.NewOTelHistogram("test_otel_kept_after_seconds", "test histogram").Record(ctx, 2)
+
+	count := func(reader *controller.Controller) map[string]uint64 {
+		r.NoError(reader.Collect(ctx))
+		counts := map[string]uint64{}
+		r.NoError(reader.ForEach(export.CumulativeExportKindSelector(), func(record export.Record) error {
+			cnt, err := record.Aggregation().(aggregation.MinMaxSumCount).Count()
+			counts[record.Descriptor().Name()] += cnt
+			return err
+		}))
+		return counts
+	}
+	counts := count(reader)
+	r.Equal(uint64(1), counts["kubevela_test_otel_kept_seconds"])
+	r.Equal(uint64(1), counts["kubevela_test_otel_kept_after_seconds"])
+	r.Empty(count(failing))
+}
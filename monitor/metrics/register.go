@@ -0,0 +1,112 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/klog/v2"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// RegisterMetrics registers the collectors into the controller-runtime
+// metrics registry. It panics if any collector cannot be registered, like
+// prometheus.MustRegister. For aliases created by NewDeprecatedAlias, a
+// deprecation warning is logged.
+func RegisterMetrics(collectors ...prometheus.Collector) {
+	for _, c := range collectors {
+		if alias, ok := c.(*deprecatedAlias); ok {
+			klog.Warningf("metric %s is deprecated, use %s instead", alias.deprecatedName, alias.name)
+		}
+	}
+	ctrlmetrics.Registry.MustRegister(collectors...)
+}
+
+// deprecatedAlias mirrors the metrics of the collector under the deprecated name
+type deprecatedAlias struct {
+	collector      prometheus.Collector
+	name           string
+	deprecatedName string
+}
+
+// NewDeprecatedAlias creates a collector which mirrors all the observations
+// of the collector c, named as name, under the deprecatedName. It can be used
+// to keep the old metric name during renaming, so that dashboards can migrate
+// without a gap. Both the collector and the alias need to be registered.
+func NewDeprecatedAlias(c prometheus.Collector, name string, deprecatedName string) prometheus.Collector {
+	return &deprecatedAlias{collector: c, name: name, deprecatedName: deprecatedName}
+}
+
+// Describe sends no descriptor, which makes the alias an unchecked collector
+// as its label dimensions are only known from the mirrored metrics.
+func (in *deprecatedAlias) Describe(chan<- *prometheus.Desc) {}
+
+// Collect mirrors the metrics from the collector under the deprecated name
+func (in *deprecatedAlias) Collect(ch chan<- prometheus.Metric) {
+	metrics := make(chan prometheus.Metric)
+	go func() {
+		in.collector.Collect(metrics)
+		close(metrics)
+	}()
+	for m := range metrics {
+		alias, err := in.mirror(m)
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(prometheus.NewDesc(in.deprecatedName, "", nil, nil), err)
+			continue
+		}
+		ch <- alias
+	}
+}
+
+func (in *deprecatedAlias) mirror(m prometheus.Metric) (prometheus.Metric, error) {
+	pb := &dto.Metric{}
+	if err := m.Write(pb); err != nil {
+		return nil, err
+	}
+	sort.Slice(pb.Label, func(i, j int) bool { return pb.Label[i].GetName() < pb.Label[j].GetName() })
+	var names, values []string
+	for _, label := range pb.Label {
+		names = append(names, label.GetName())
+		values = append(values, label.GetValue())
+	}
+	desc := prometheus.NewDesc(in.deprecatedName, fmt.Sprintf("Deprecated: use %s instead", in.name), names, nil)
+	switch {
+	case pb.Counter != nil:
+		return prometheus.NewConstMetric(desc, prometheus.CounterValue, pb.Counter.GetValue(), values...)
+	case pb.Gauge != nil:
+		return prometheus.NewConstMetric(desc, prometheus.GaugeValue, pb.Gauge.GetValue(), values...)
+	case pb.Untyped != nil:
+		return prometheus.NewConstMetric(desc, prometheus.UntypedValue, pb.Untyped.GetValue(), values...)
+	case pb.Histogram != nil:
+		buckets := map[float64]uint64{}
+		for _, b := range pb.Histogram.Bucket {
+			buckets[b.GetUpperBound()] = b.GetCumulativeCount()
+		}
+		return prometheus.NewConstHistogram(desc, pb.Histogram.GetSampleCount(), pb.Histogram.GetSampleSum(), buckets, values...)
+	case pb.Summary != nil:
+		quantiles := map[float64]float64{}
+		for _, q := range pb.Summary.Quantile {
+			quantiles[q.GetQuantile()] = q.GetValue()
+		}
+		return prometheus.NewConstSummary(desc, pb.Summary.GetSampleCount(), pb.Summary.GetSampleSum(), quantiles, values...)
+	default:
+		return nil, fmt.Errorf("unsupported metric type for alias %s", in.deprecatedName)
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics_test
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+)
+
+func gatherMetricFamilies(t *testing.T, registry prometheus.Gatherer) map[string]*dto.MetricFamily {
+	mfs, err := registry.Gather()
+	require.NoError(t, err)
+	m := map[string]*dto.MetricFamily{}
+	for _, mf := range mfs {
+		m[mf.GetName()] = mf
+	}
+	return m
+}
+
+func TestDeprecatedAlias(t *testing.T) {
+	histogram := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "test_new_seconds",
+		Help: "test histogram",
+	}, []string{"controller"})
+	counter := prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        "test_new_total",
+		Help:        "test counter",
+		ConstLabels: prometheus.Labels{"source": "test"},
+	})
+	// a private registry keeps the test repeatable
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		histogram, metrics.NewDeprecatedAlias(histogram, "test_new_seconds", "test_old_seconds"),
+		counter, metrics.NewDeprecatedAlias(counter, "test_new_total", "test_old_total"),
+	)
+	histogram.WithLabelValues("app").Observe(0.5)
+	histogram.WithLabelValues("app").Observe(1.5)
+	counter.Add(3)
+
+	mfs := gatherMetricFamilies(t, registry)
+	r := require.New(t)
+	r.Contains(mfs, "test_old_seconds")
+	r.Equal(dto.MetricType_HISTOGRAM, mfs["test_old_seconds"].GetType())
+	r.Equal("Deprecated: use test_new_seconds instead", mfs["test_old_seconds"].GetHelp())
+	cur, old := mfs["test_new_seconds"].Metric[0], mfs["test_old_seconds"].Metric[0]
+	r.Equal(uint64(2), old.GetHistogram().GetSampleCount())
+	r.Equal(cur.GetHistogram().GetSampleSum(), old.GetHistogram().GetSampleSum())
+	r.Equal(cur.GetHistogram().GetBucket(), old.GetHistogram().GetBucket())
+	r.Equal(cur.GetLabel(), old.GetLabel())
+
+	r.Contains(mfs, "test_old_total")
+	r.Equal(3.0, mfs["test_old_total"].Metric[0].GetCounter().GetValue())
+	r.Equal("source", mfs["test_old_total"].Metric[0].GetLabel()[0].GetName())
+}
@@ -18,6 +18,26 @@ package compression
 
 import "encoding/base64"
 
+// Compress compresses the raw data using the given compression type. Unlike
+// CompressedBytes, the data is not JSON-marshaled, so the result can be read
+// by any decompressor of the type.
+func Compress(t Type, data []byte) ([]byte, error) {
+	comp, ok := compressors[t]
+	if !ok {
+		return nil, NewUnsupportedCompressionTypeError(string(t))
+	}
+	return comp.compressRaw(data)
+}
+
+// Decompress decompresses the data compressed by Compress.
+func Decompress(t Type, compressed []byte) ([]byte, error) {
+	comp, ok := compressors[t]
+	if !ok {
+		return nil, NewUnsupportedCompressionTypeError(string(t))
+	}
+	return comp.decompressRaw(compressed)
+}
+
 // CompressedBytes represents compressed data and which compression method is used.
 // It stores compressed data in binary form.
 type CompressedBytes struct {
@@ -17,6 +17,9 @@ limitations under the License.
 package compression
 
 import (
+	"bytes"
+	"compress/gzip"
+	"io"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -49,3 +52,28 @@ func TestCompressedText_EncodeFrom(t *testing.T) {
 	err = c.EncodeFrom("")
 	assert.NoError(t, err)
 }
+
+func TestCompress(t *testing.T) {
+	data := []byte("raw data, raw data, raw data")
+	for _, typ := range []Type{Gzip, Zstd} {
+		compressed, err := Compress(typ, data)
+		assert.NoError(t, err)
+		decompressed, err := Decompress(typ, compressed)
+		assert.NoError(t, err)
+		assert.Equal(t, data, decompressed)
+	}
+
+	// raw data is compressed as is, so it can be read by plain readers
+	compressed, err := Compress(Gzip, data)
+	assert.NoError(t, err)
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	assert.NoError(t, err)
+	decompressed, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, data, decompressed)
+
+	_, err = Compress("unsupported", data)
+	assert.Error(t, err)
+	_, err = Decompress("unsupported", data)
+	assert.Error(t, err)
+}
@@ -34,28 +34,36 @@ func (c *gzipCompressor) compress(obj interface{}) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	return c.compressRaw(bs)
+}
+
+func (c *gzipCompressor) decompress(compressed []byte, obj interface{}) error {
+	bs, err := c.decompressRaw(compressed)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bs, obj)
+}
+
+func (c *gzipCompressor) compressRaw(data []byte) ([]byte, error) {
 	var b bytes.Buffer
 	gz := gzip.NewWriter(&b)
-	if _, err = gz.Write(bs); err != nil {
+	if _, err := gz.Write(data); err != nil {
 		return nil, err
 	}
-	if err = gz.Flush(); err != nil {
+	if err := gz.Flush(); err != nil {
 		return nil, err
 	}
-	if err = gz.Close(); err != nil {
+	if err := gz.Close(); err != nil {
 		return nil, err
 	}
 	return b.Bytes(), nil
 }
 
-func (c *gzipCompressor) decompress(compressed []byte, obj interface{}) error {
+func (c *gzipCompressor) decompressRaw(compressed []byte) ([]byte, error) {
 	reader, err := gzip.NewReader(bytes.NewReader(compressed))
 	if err != nil {
-		return err
-	}
-	bs, err := io.ReadAll(reader)
-	if err != nil {
-		return err
+		return nil, err
 	}
-	return json.Unmarshal(bs, obj)
+	return io.ReadAll(reader)
 }
@@ -37,6 +37,10 @@ type compressor interface {
 	compress(obj interface{}) ([]byte, error)
 	// decompress decompresses the data, then unmarshalls it using JSON.
 	decompress(compressed []byte, obj interface{}) error
+	// compressRaw compresses the raw data as is.
+	compressRaw(data []byte) ([]byte, error)
+	// decompressRaw decompresses the data into the raw data.
+	decompressRaw(compressed []byte) ([]byte, error)
 	init()
 }
 
@@ -83,15 +83,22 @@ func (c *zstdCompressor) compress(obj interface{}) ([]byte, error) {
 		return nil, err
 	}
 
-	compressed := c.encoder.EncodeAll(bs, make([]byte, 0, len(bs)))
-	return compressed, nil
+	return c.compressRaw(bs)
 }
 
 func (c *zstdCompressor) decompress(compressed []byte, obj interface{}) error {
-	decompressed, err := c.decoder.DecodeAll(compressed, nil)
+	decompressed, err := c.decompressRaw(compressed)
 	if err != nil {
 		return err
 	}
 
 	return json.Unmarshal(decompressed, obj)
 }
+
+func (c *zstdCompressor) compressRaw(data []byte) ([]byte, error) {
+	return c.encoder.EncodeAll(data, make([]byte, 0, len(data))), nil
+}
+
+func (c *zstdCompressor) decompressRaw(compressed []byte) ([]byte, error) {
+	return c.decoder.DecodeAll(compressed, nil)
+}
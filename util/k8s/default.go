@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ApplyDefaults applies the defaulting functions registered in the scheme to
+// the object, like the apiserver does on write, which can be used to preview
+// the object to be persisted. Unstructured objects are defaulted on a copy of
+// the typed ones registered in the scheme, and only the fields changed by the
+// defaulting are merged back, so that the fields unknown to the typed object
+// are kept. Objects without registered types or defaulters are left unchanged.
+func ApplyDefaults(obj client.Object, scheme *runtime.Scheme) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		scheme.Default(obj)
+		return nil
+	}
+	gvk := u.GroupVersionKind()
+	typed, err := scheme.New(gvk)
+	if err != nil {
+		if runtime.IsNotRegisteredError(err) {
+			return nil
+		}
+		return err
+	}
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, typed); err != nil {
+		return err
+	}
+	before, err := runtime.DefaultUnstructuredConverter.ToUnstructured(typed)
+	if err != nil {
+		return err
+	}
+	scheme.Default(typed)
+	after, err := runtime.DefaultUnstructuredConverter.ToUnstructured(typed)
+	if err != nil {
+		return err
+	}
+	u.SetUnstructuredContent(mergeDefaults(u.Object, before, after).(map[string]interface{}))
+	return nil
+}
+
+// mergeDefaults merges the changes from before to after, made by the
+// defaulting, into dst. Maps and lists of the same length are merged
+// recursively to keep the unknown fields in dst, other values are replaced.
+// Maps missing in dst are only added if they contain defaulted fields.
+func mergeDefaults(dst, before, after interface{}) interface{} {
+	if reflect.DeepEqual(before, after) {
+		return dst
+	}
+	switch afterVal := after.(type) {
+	case map[string]interface{}:
+		dstMap, ok := dst.(map[string]interface{})
+		if !ok {
+			return after
+		}
+		beforeMap, _ := before.(map[string]interface{})
+		for key, afterSub := range afterVal {
+			dstSub, found := dstMap[key]
+			switch {
+			case found:
+				dstMap[key] = mergeDefaults(dstSub, beforeMap[key], afterSub)
+			case isMap(afterSub):
+				if merged := mergeDefaults(map[string]interface{}{}, beforeMap[key], afterSub).(map[string]interface{}); len(merged) > 0 {
+					dstMap[key] = merged
+				}
+			case !reflect.DeepEqual(beforeMap[key], afterSub):
+				dstMap[key] = afterSub
+			}
+		}
+		return dstMap
+	case []interface{}:
+		dstList, ok := dst.([]interface{})
+		beforeList, _ := before.([]interface{})
+		if !ok || len(dstList) != len(afterVal) || len(beforeList) != len(afterVal) {
+			return after
+		}
+		for i := range afterVal {
+			dstList[i] = mergeDefaults(dstList[i], beforeList[i], afterVal[i])
+		}
+		return dstList
+	default:
+		return after
+	}
+}
+
+func isMap(val interface{}) bool {
+	_, ok := val.(map[string]interface{})
+	return ok
+}
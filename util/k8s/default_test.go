@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+
+	"github.com/kubevela/pkg/util/k8s"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	r := require.New(t)
+	scheme := runtime.NewScheme()
+	r.NoError(appsv1.AddToScheme(scheme))
+	r.NoError(corev1.AddToScheme(scheme))
+	scheme.AddTypeDefaultingFunc(&appsv1.Deployment{}, func(obj interface{}) {
+		deploy := obj.(*appsv1.Deployment)
+		if deploy.Spec.Replicas == nil {
+			deploy.Spec.Replicas = pointer.Int32(1)
+		}
+		for i := range deploy.Spec.Template.Spec.Containers {
+			if deploy.Spec.Template.Spec.Containers[i].ImagePullPolicy == "" {
+				deploy.Spec.Template.Spec.Containers[i].ImagePullPolicy = corev1.PullIfNotPresent
+			}
+		}
+	})
+
+	deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "example"}}
+	r.NoError(k8s.ApplyDefaults(deploy, scheme))
+	r.Equal(int32(1), *deploy.Spec.Replicas)
+
+	// existing values are kept
+	deploy.Spec.Replicas = pointer.Int32(3)
+	r.NoError(k8s.ApplyDefaults(deploy, scheme))
+	r.Equal(int32(3), *deploy.Spec.Replicas)
+
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("Deployment"))
+	u.SetName("example")
+	r.NoError(k8s.ApplyDefaults(u, scheme))
+	r.Equal(map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "example"},
+		"spec":       map[string]interface{}{"replicas": int64(1)},
+	}, u.Object)
+
+	// unknown fields are kept and only the defaulted fields are added
+	u = &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "example"},
+		"spec": map[string]interface{}{
+			"replicas":     int64(2),
+			"unknownField": "value",
+			"template": map[string]interface{}{"spec": map[string]interface{}{"containers": []interface{}{
+				map[string]interface{}{"name": "main", "image": "nginx", "unknownField": "value"},
+			}}},
+		},
+	}}
+	r.NoError(k8s.ApplyDefaults(u, scheme))
+	r.Equal(map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "example"},
+		"spec": map[string]interface{}{
+			"replicas":     int64(2),
+			"unknownField": "value",
+			"template": map[string]interface{}{"spec": map[string]interface{}{"containers": []interface{}{
+				map[string]interface{}{"name": "main", "image": "nginx", "unknownField": "value", "imagePullPolicy": "IfNotPresent"},
+			}}},
+		},
+	}, u.Object)
+
+	// types without defaulters or not registered are left unchanged
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "example"}}
+	r.NoError(k8s.ApplyDefaults(cm, scheme))
+	r.Equal(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "example"}}, cm)
+	unknown := &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "example.com/v1", "kind": "Unknown"}}
+	r.NoError(k8s.ApplyDefaults(unknown, scheme))
+	r.Equal(map[string]interface{}{"apiVersion": "example.com/v1", "kind": "Unknown"}, unknown.Object)
+}
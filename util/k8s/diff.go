@@ -0,0 +1,277 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DiffObjects returns the sorted paths of the fields which differ between
+// the two objects, such as ".metadata.labels.app". Fields only existing in
+// one object are included, and lists are compared as a whole.
+// If the JSON serialization of either object exceeds threshold bytes, the
+// objects are compared as token streams read from the serializations: fields
+// in the same order are compared token by token, and only the changed values
+// and the fields in different orders are decoded. The memory used beyond the
+// serializations is therefore bounded by the changed parts instead of the
+// object size. Otherwise, both objects are
+// fully decoded and compared. Non-positive threshold disables the streaming
+// comparison. Both paths return the same result.
+func DiffObjects(a, b runtime.Object, threshold int) ([]string, error) {
+	aBytes, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	bBytes, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	if threshold > 0 && (len(aBytes) > threshold || len(bBytes) > threshold) {
+		err = diffStream("", json.NewDecoder(bytes.NewReader(aBytes)), json.NewDecoder(bytes.NewReader(bBytes)), &paths)
+	} else {
+		var aVal, bVal interface{}
+		if err = json.Unmarshal(aBytes, &aVal); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(bBytes, &bVal); err != nil {
+			return nil, err
+		}
+		diffValue("", aVal, bVal, &paths)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// diffValue records the paths of differing fields between decoded values
+func diffValue(path string, a, b interface{}, paths *[]string) {
+	aMap, aIsMap := a.(map[string]interface{})
+	bMap, bIsMap := b.(map[string]interface{})
+	if !aIsMap || !bIsMap {
+		if !reflect.DeepEqual(a, b) {
+			*paths = append(*paths, path)
+		}
+		return
+	}
+	for key, aSub := range aMap {
+		bSub, found := bMap[key]
+		if !found {
+			*paths = append(*paths, path+"."+key)
+			continue
+		}
+		diffValue(path+"."+key, aSub, bSub, paths)
+	}
+	for key := range bMap {
+		if _, found := aMap[key]; !found {
+			*paths = append(*paths, path+"."+key)
+		}
+	}
+}
+
+// diffStream records the paths of differing fields between the JSON values
+// read from the decoders. Objects are compared field by field as the tokens
+// are read, and fields at the same position in both objects are compared
+// without being decoded. Only the fields in different orders and the scalar
+// values are decoded, so identical subtrees are never held in memory as
+// decoded values.
+func diffStream(path string, a, b *json.Decoder, paths *[]string) error {
+	aTok, err := a.Token()
+	if err != nil {
+		return err
+	}
+	bTok, err := b.Token()
+	if err != nil {
+		return err
+	}
+	if aTok == json.Delim('{') && bTok == json.Delim('{') {
+		return diffStreamObject(path, a, b, paths)
+	}
+	if aTok == json.Delim('[') && bTok == json.Delim('[') {
+		return diffStreamList(path, a, b, paths)
+	}
+	aVal, err := decodeStreamValue(a, aTok)
+	if err != nil {
+		return err
+	}
+	bVal, err := decodeStreamValue(b, bTok)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(aVal, bVal) {
+		*paths = append(*paths, path)
+	}
+	return nil
+}
+
+// diffStreamObject compares the fields of the objects whose opening delimiters
+// are read. Fields with the same key in the same position are compared as
+// streams. Otherwise, the fields are decoded and held until the field with
+// the same key is read from the other object.
+func diffStreamObject(path string, a, b *json.Decoder, paths *[]string) error {
+	aPending, bPending := map[string]interface{}{}, map[string]interface{}{}
+	for a.More() || b.More() {
+		aKey, aFound, err := readStreamKey(a)
+		if err != nil {
+			return err
+		}
+		bKey, bFound, err := readStreamKey(b)
+		if err != nil {
+			return err
+		}
+		if aFound && bFound && aKey == bKey {
+			if err = diffStream(path+"."+aKey, a, b, paths); err != nil {
+				return err
+			}
+			continue
+		}
+		if aFound {
+			if err = diffStreamPending(path, aKey, a, aPending, bPending, paths); err != nil {
+				return err
+			}
+		}
+		if bFound {
+			if err = diffStreamPending(path, bKey, b, bPending, aPending, paths); err != nil {
+				return err
+			}
+		}
+	}
+	for _, dec := range []*json.Decoder{a, b} {
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	}
+	for key := range aPending {
+		*paths = append(*paths, path+"."+key)
+	}
+	for key := range bPending {
+		*paths = append(*paths, path+"."+key)
+	}
+	return nil
+}
+
+// diffStreamPending decodes the field value and compares it with the field of
+// the same key held from the other object, or holds it if not found
+func diffStreamPending(path, key string, dec *json.Decoder, pending, otherPending map[string]interface{}, paths *[]string) error {
+	val, err := readStreamValue(dec)
+	if err != nil {
+		return err
+	}
+	if otherVal, found := otherPending[key]; found {
+		delete(otherPending, key)
+		diffValue(path+"."+key, val, otherVal, paths)
+		return nil
+	}
+	pending[key] = val
+	return nil
+}
+
+// diffStreamList compares the lists whose opening delimiters are read. Lists
+// are compared as a whole, so the path of the list is recorded if any items
+// differ.
+func diffStreamList(path string, a, b *json.Decoder, paths *[]string) error {
+	var itemPaths []string
+	for a.More() && b.More() {
+		if err := diffStream(path, a, b, &itemPaths); err != nil {
+			return err
+		}
+	}
+	differ := len(itemPaths) > 0
+	for _, dec := range []*json.Decoder{a, b} {
+		for dec.More() {
+			differ = true
+			if _, err := readStreamValue(dec); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	}
+	if differ {
+		*paths = append(*paths, path)
+	}
+	return nil
+}
+
+// readStreamKey reads the next object key if the object has more fields
+func readStreamKey(dec *json.Decoder) (string, bool, error) {
+	if !dec.More() {
+		return "", false, nil
+	}
+	tok, err := dec.Token()
+	if err != nil {
+		return "", false, err
+	}
+	key, ok := tok.(string)
+	if !ok {
+		return "", false, fmt.Errorf("invalid JSON object key %v", tok)
+	}
+	return key, true, nil
+}
+
+// readStreamValue reads and decodes the next value
+func readStreamValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	return decodeStreamValue(dec, tok)
+}
+
+// decodeStreamValue decodes the value starting with the read token, in the
+// same form as json.Unmarshal into interface{}
+func decodeStreamValue(dec *json.Decoder, tok json.Token) (interface{}, error) {
+	switch tok {
+	case json.Delim('{'):
+		obj := map[string]interface{}{}
+		for {
+			key, found, err := readStreamKey(dec)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				break
+			}
+			if obj[key], err = readStreamValue(dec); err != nil {
+				return nil, err
+			}
+		}
+		_, err := dec.Token()
+		return obj, err
+	case json.Delim('['):
+		list := []interface{}{}
+		for dec.More() {
+			item, err := readStreamValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		_, err := dec.Token()
+		return list, err
+	}
+	return tok, nil
+}
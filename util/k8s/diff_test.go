@@ -0,0 +1,138 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/kubevela/pkg/util/k8s"
+)
+
+func newLargeConfigMap(size int) *corev1.ConfigMap {
+	cm := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "large"},
+		Data:       map[string]string{},
+	}
+	for i := 0; i < size; i++ {
+		cm.Data[fmt.Sprintf("key-%d", i)] = strings.Repeat("v", 64)
+	}
+	return cm
+}
+
+func TestDiffObjects(t *testing.T) {
+	large := newLargeConfigMap(100)
+	largeChanged := large.DeepCopy()
+	largeChanged.Data["key-1"] = "changed"
+	delete(largeChanged.Data, "key-2")
+	largeChanged.Data["key-new"] = "new"
+	largeChanged.Labels = map[string]string{"app": "example"}
+
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "example"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "main", Image: "nginx:1"}},
+		}}},
+	}
+	deployChanged := deploy.DeepCopy()
+	deployChanged.Spec.Template.Spec.Containers[0].Image = "nginx:2"
+	deployExtended := deploy.DeepCopy()
+	deployExtended.Spec.Template.Spec.Containers = append(deployExtended.Spec.Template.Spec.Containers, corev1.Container{Name: "sidecar"})
+
+	escaped := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{`a"b`: `{"x": [1, "}"]}`}}}
+	escapedChanged := escaped.DeepCopy()
+	escapedChanged.Annotations[`a"b`] = `\"]`
+	escapedChanged.Annotations["c<d"] = "e"
+
+	u := &unstructured.Unstructured{}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(large)
+	require.NoError(t, err)
+	u.SetUnstructuredContent(content)
+	uChanged := u.DeepCopy()
+	uChanged.SetAnnotations(map[string]string{"app.oam.dev/name": "example"})
+	uChanged.Object["data"].(map[string]interface{})["key-1"] = "changed"
+	typedChanged := large.DeepCopy()
+	typedChanged.Annotations = map[string]string{"app.oam.dev/name": "other"}
+	typedChanged.Data["key-3"] = "changed"
+
+	testcases := map[string]struct {
+		a, b     runtime.Object
+		expected []string
+	}{
+		"identical": {
+			a: large, b: large.DeepCopy(),
+		},
+		"changed-keys": {
+			a: large, b: largeChanged,
+			expected: []string{".data.key-1", ".data.key-2", ".data.key-new", ".metadata.labels"},
+		},
+		"changed-list": {
+			a: deploy, b: deployChanged,
+			expected: []string{".spec.template.spec.containers"},
+		},
+		"extended-list": {
+			a: deploy, b: deployExtended,
+			expected: []string{".spec.template.spec.containers"},
+		},
+		"escaped": {
+			a: escaped, b: escapedChanged,
+			expected: []string{`.metadata.annotations.a"b`, ".metadata.annotations.c<d"},
+		},
+		"typed-and-unstructured": {
+			a: large, b: u,
+		},
+		"reordered-fields": {
+			a: typedChanged, b: uChanged,
+			expected: []string{".data.key-1", ".data.key-3", ".metadata.annotations.app.oam.dev/name"},
+		},
+	}
+	for name, tt := range testcases {
+		t.Run(name, func(t *testing.T) {
+			naive, err := k8s.DiffObjects(tt.a, tt.b, 0)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, naive)
+			structural, err := k8s.DiffObjects(tt.a, tt.b, 1)
+			require.NoError(t, err)
+			require.Equal(t, naive, structural)
+		})
+	}
+}
+
+func BenchmarkDiffObjects(b *testing.B) {
+	large := newLargeConfigMap(10000)
+	changed := large.DeepCopy()
+	changed.Data["key-1"] = "changed"
+	for name, threshold := range map[string]int{"naive": 0, "structural": 1} {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := k8s.DiffObjects(large, changed, threshold); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
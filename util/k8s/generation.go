@@ -0,0 +1,70 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// IsUpToDate check if the status of the object has observed the latest
+// generation, i.e. metadata.generation equals status.observedGeneration.
+// Objects without status.observedGeneration are never up-to-date.
+func IsUpToDate(obj client.Object) (bool, error) {
+	var content map[string]interface{}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		content = u.Object
+	} else {
+		var err error
+		if content, err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj); err != nil {
+			return false, err
+		}
+	}
+	val, found, err := unstructured.NestedFieldNoCopy(content, "status", "observedGeneration")
+	if err != nil || !found {
+		return false, err
+	}
+	var observedGeneration int64
+	switch v := val.(type) {
+	case int64:
+		observedGeneration = v
+	case int:
+		observedGeneration = int64(v)
+	case float64:
+		observedGeneration = int64(v)
+	default:
+		return false, fmt.Errorf("invalid type %T for status.observedGeneration", val)
+	}
+	return obj.GetGeneration() == observedGeneration, nil
+}
+
+// OutdatedPredicate filters out the update events of objects which are
+// up-to-date, such as the status updates after the latest generation is
+// reconciled. Objects failed to be checked are not filtered.
+func OutdatedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			upToDate, err := IsUpToDate(e.ObjectNew)
+			return err != nil || !upToDate
+		},
+	}
+}
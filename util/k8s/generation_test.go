@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/kubevela/pkg/util/k8s"
+)
+
+func TestIsUpToDate(t *testing.T) {
+	testcases := map[string]struct {
+		obj      client.Object
+		expected bool
+		hasErr   bool
+	}{
+		"up-to-date": {
+			obj: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 2},
+			},
+			expected: true,
+		},
+		"stale": {
+			obj: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 3},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 2},
+			},
+			expected: false,
+		},
+		"unstructured-up-to-date": {
+			obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{"generation": int64(1)},
+				"status":   map[string]interface{}{"observedGeneration": int64(1)},
+			}},
+			expected: true,
+		},
+		"missing-observed-generation": {
+			obj:      &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Generation: 1}},
+			expected: false,
+		},
+		"invalid-observed-generation": {
+			obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"status": map[string]interface{}{"observedGeneration": "1"},
+			}},
+			hasErr: true,
+		},
+	}
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			upToDate, err := k8s.IsUpToDate(testcase.obj)
+			if testcase.hasErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, testcase.expected, upToDate)
+		})
+	}
+}
+
+func TestOutdatedPredicate(t *testing.T) {
+	p := k8s.OutdatedPredicate()
+	newDeployment := func(generation, observedGeneration int64) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Generation: generation},
+			Status:     appsv1.DeploymentStatus{ObservedGeneration: observedGeneration},
+		}
+	}
+	require.False(t, p.Update(event.UpdateEvent{ObjectOld: newDeployment(1, 0), ObjectNew: newDeployment(1, 1)}))
+	require.True(t, p.Update(event.UpdateEvent{ObjectOld: newDeployment(1, 1), ObjectNew: newDeployment(2, 1)}))
+	require.True(t, p.Create(event.CreateEvent{Object: newDeployment(1, 1)}))
+}
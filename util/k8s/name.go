@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// NamePolicy describes the naming convention that resource names must follow
+type NamePolicy struct {
+	// Prefix the required prefix of the name
+	Prefix string
+	// Suffix the required suffix of the name
+	Suffix string
+	// MaxLength the max length of the name. If not positive, no limit.
+	MaxLength int
+	// AllowedCharacters matches a single character that is allowed in the
+	// name, such as `[a-z0-9-]`. If nil, all characters are allowed.
+	AllowedCharacters *regexp.Regexp
+}
+
+// ValidateName check if the name follows the given NamePolicy
+// All violations are returned together as an aggregated error.
+func ValidateName(name string, policy NamePolicy) error {
+	var errs []error
+	if policy.Prefix != "" && !strings.HasPrefix(name, policy.Prefix) {
+		errs = append(errs, fmt.Errorf("name %q must have prefix %q", name, policy.Prefix))
+	}
+	if policy.Suffix != "" && !strings.HasSuffix(name, policy.Suffix) {
+		errs = append(errs, fmt.Errorf("name %q must have suffix %q", name, policy.Suffix))
+	}
+	if policy.MaxLength > 0 && len(name) > policy.MaxLength {
+		errs = append(errs, fmt.Errorf("name %q is %d characters long, exceeding max length %d", name, len(name), policy.MaxLength))
+	}
+	if invalid := invalidNameCharacters(name, policy); invalid != "" {
+		errs = append(errs, fmt.Errorf("name %q contains characters %q not allowed by %s", name, invalid, policy.AllowedCharacters.String()))
+	}
+	return kerrors.NewAggregate(errs)
+}
+
+const (
+	// generatedNameSuffixLength the length of the random suffix appended by
+	// the apiserver to the generateName
+	generatedNameSuffixLength = 5
+	// generatedNameSuffixCharacters the characters used by the random suffix
+	generatedNameSuffixCharacters = "bcdfghjklmnpqrstvwxz2456789"
+	// maxGenerateNameLength the max length of the generateName kept by the
+	// apiserver, which truncates the longer ones
+	maxGenerateNameLength = 63 - generatedNameSuffixLength
+)
+
+// ValidateGenerateName check if the names generated by the apiserver from the
+// generateName follow the given NamePolicy. The generated names end with a
+// random suffix, so the generateName must leave room for it under the
+// MaxLength, and the policy cannot require a Suffix.
+func ValidateGenerateName(generateName string, policy NamePolicy) error {
+	var errs []error
+	if policy.Prefix != "" && !strings.HasPrefix(generateName, policy.Prefix) {
+		errs = append(errs, fmt.Errorf("generateName %q must have prefix %q", generateName, policy.Prefix))
+	}
+	if policy.Suffix != "" {
+		errs = append(errs, fmt.Errorf("generateName %q cannot produce names with suffix %q", generateName, policy.Suffix))
+	}
+	base := generateName
+	if len(base) > maxGenerateNameLength {
+		base = base[:maxGenerateNameLength]
+	}
+	if length := len(base) + generatedNameSuffixLength; policy.MaxLength > 0 && length > policy.MaxLength {
+		errs = append(errs, fmt.Errorf("generateName %q produces names of %d characters long, exceeding max length %d", generateName, length, policy.MaxLength))
+	}
+	if invalid := invalidNameCharacters(generateName, policy); invalid != "" {
+		errs = append(errs, fmt.Errorf("generateName %q contains characters %q not allowed by %s", generateName, invalid, policy.AllowedCharacters.String()))
+	}
+	if invalid := invalidNameCharacters(generatedNameSuffixCharacters, policy); invalid != "" {
+		errs = append(errs, fmt.Errorf("generateName %q produces random suffixes with characters %q not allowed by %s", generateName, invalid, policy.AllowedCharacters.String()))
+	}
+	return kerrors.NewAggregate(errs)
+}
+
+// invalidNameCharacters returns the characters in the name not allowed by the
+// NamePolicy
+func invalidNameCharacters(name string, policy NamePolicy) string {
+	if policy.AllowedCharacters == nil {
+		return ""
+	}
+	var invalid []string
+	for _, c := range name {
+		if s := string(c); !policy.AllowedCharacters.MatchString(s) {
+			invalid = append(invalid, s)
+		}
+	}
+	return strings.Join(invalid, "")
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubevela/pkg/util/k8s"
+)
+
+func TestValidateName(t *testing.T) {
+	policy := k8s.NamePolicy{
+		Prefix:            "vela-",
+		Suffix:            "-app",
+		MaxLength:         16,
+		AllowedCharacters: regexp.MustCompile(`[a-z0-9-]`),
+	}
+	testcases := map[string]struct {
+		name    string
+		policy  k8s.NamePolicy
+		wantErr []string
+	}{
+		"valid": {
+			name:   "vela-test-app",
+			policy: policy,
+		},
+		"empty-policy": {
+			name:   "Any_Name",
+			policy: k8s.NamePolicy{},
+		},
+		"missing-prefix": {
+			name:    "test-app",
+			policy:  policy,
+			wantErr: []string{`name "test-app" must have prefix "vela-"`},
+		},
+		"missing-suffix": {
+			name:    "vela-test",
+			policy:  policy,
+			wantErr: []string{`name "vela-test" must have suffix "-app"`},
+		},
+		"too-long": {
+			name:    "vela-very-long-app",
+			policy:  policy,
+			wantErr: []string{`name "vela-very-long-app" is 18 characters long, exceeding max length 16`},
+		},
+		"invalid-characters": {
+			name:    "vela-T_st-app",
+			policy:  policy,
+			wantErr: []string{`name "vela-T_st-app" contains characters "T_" not allowed by [a-z0-9-]`},
+		},
+		"multiple-violations": {
+			name:   "Test",
+			policy: policy,
+			wantErr: []string{
+				`name "Test" must have prefix "vela-"`,
+				`name "Test" must have suffix "-app"`,
+				`name "Test" contains characters "T" not allowed by [a-z0-9-]`,
+			},
+		},
+	}
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			err := k8s.ValidateName(testcase.name, testcase.policy)
+			if len(testcase.wantErr) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, msg := range testcase.wantErr {
+				require.Contains(t, err.Error(), msg)
+			}
+		})
+	}
+}
+
+func TestValidateGenerateName(t *testing.T) {
+	policy := k8s.NamePolicy{
+		Prefix:            "vela-",
+		MaxLength:         16,
+		AllowedCharacters: regexp.MustCompile(`[a-z0-9-]`),
+	}
+	testcases := map[string]struct {
+		generateName string
+		policy       k8s.NamePolicy
+		wantErr      []string
+	}{
+		"valid": {
+			generateName: "vela-test-",
+			policy:       policy,
+		},
+		"missing-prefix": {
+			generateName: "test-",
+			policy:       policy,
+			wantErr:      []string{`generateName "test-" must have prefix "vela-"`},
+		},
+		"no-room-for-random-suffix": {
+			generateName: "vela-example-",
+			policy:       policy,
+			wantErr:      []string{`generateName "vela-example-" produces names of 18 characters long, exceeding max length 16`},
+		},
+		"truncated": {
+			generateName: "vela-" + strings.Repeat("a", 60),
+			policy:       k8s.NamePolicy{MaxLength: 63},
+		},
+		"required-suffix": {
+			generateName: "vela-",
+			policy:       k8s.NamePolicy{Suffix: "-app"},
+			wantErr:      []string{`generateName "vela-" cannot produce names with suffix "-app"`},
+		},
+		"invalid-characters": {
+			generateName: "vela-T_",
+			policy:       policy,
+			wantErr:      []string{`generateName "vela-T_" contains characters "T_" not allowed by [a-z0-9-]`},
+		},
+		"invalid-random-suffix": {
+			generateName: "vela-",
+			policy:       k8s.NamePolicy{AllowedCharacters: regexp.MustCompile(`[a-z-]`)},
+			wantErr:      []string{`generateName "vela-" produces random suffixes with characters "2456789" not allowed by [a-z-]`},
+		},
+	}
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			err := k8s.ValidateGenerateName(testcase.generateName, testcase.policy)
+			if len(testcase.wantErr) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, msg := range testcase.wantErr {
+				require.Contains(t, err.Error(), msg)
+			}
+		})
+	}
+}
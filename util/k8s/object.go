@@ -52,6 +52,21 @@ func IsUnstructuredObject(obj runtime.Object) bool {
 	return isUnstructured || isUnstructuredList
 }
 
+// NormalizeAPIVersion canonicalize the apiVersion string, so that the same
+// group version is always represented in the same way. The core group can be
+// written as "v1", "/v1" or "core/v1", all of which are normalized to "v1".
+func NormalizeAPIVersion(gv string) string {
+	gv = strings.TrimSpace(gv)
+	group, version, found := strings.Cut(gv, "/")
+	if !found {
+		return gv
+	}
+	if group == "" || group == "core" {
+		return version
+	}
+	return gv
+}
+
 // AddAnnotation add annotation to runtime.Object
 func AddAnnotation(obj runtime.Object, key, value string) error {
 	metadataAccessor := meta.NewAccessor()
@@ -58,6 +58,43 @@ func TestGetKindForObject(t *testing.T) {
 	}
 }
 
+func TestNormalizeAPIVersion(t *testing.T) {
+	testcases := map[string]struct {
+		gv       string
+		expected string
+	}{
+		"core": {
+			gv:       "v1",
+			expected: "v1",
+		},
+		"core-with-empty-group": {
+			gv:       "/v1",
+			expected: "v1",
+		},
+		"core-with-group-name": {
+			gv:       "core/v1",
+			expected: "v1",
+		},
+		"grouped": {
+			gv:       "apps/v1",
+			expected: "apps/v1",
+		},
+		"empty": {
+			gv:       "",
+			expected: "",
+		},
+		"empty-group-version": {
+			gv:       "/",
+			expected: "",
+		},
+	}
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, testcase.expected, k8s.NormalizeAPIVersion(testcase.gv))
+		})
+	}
+}
+
 func TestIsUnstructuredObject(t *testing.T) {
 	testcases := map[string]struct {
 		obj      runtime.Object
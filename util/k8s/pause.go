@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+)
+
+const (
+	// ControllerPausedSkippedKey metrics key for counting the events skipped
+	// as the objects are paused
+	ControllerPausedSkippedKey = "controller_paused_skipped_total"
+)
+
+var (
+	// PauseAnnotation the default annotation for pausing the reconciles of
+	// the object, used when no annotation is specified
+	PauseAnnotation = "controller.kubevela.io/pause"
+)
+
+var (
+	// controllerPausedSkipped the paused objects metrics
+	controllerPausedSkipped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerPausedSkippedKey,
+			Help:      "number of events skipped as the objects are paused",
+		}, []string{"kind"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerPausedSkipped)
+}
+
+// IsPaused check if the reconciles of the object are paused, i.e. the pause
+// annotation is set to true. If pauseAnnotation is empty, PauseAnnotation is
+// used.
+func IsPaused(obj client.Object, pauseAnnotation string) bool {
+	if pauseAnnotation == "" {
+		pauseAnnotation = PauseAnnotation
+	}
+	paused, _ := strconv.ParseBool(obj.GetAnnotations()[pauseAnnotation])
+	return paused
+}
+
+// PausedPredicate filters out the events of paused objects, which is checked
+// by IsPaused with the pauseAnnotation. For update events, the new object is
+// checked, so that removing the annotation resumes the reconciles.
+func PausedPredicate(pauseAnnotation string) predicate.Predicate {
+	filter := func(obj client.Object) bool {
+		if obj == nil || !IsPaused(obj, pauseAnnotation) {
+			return true
+		}
+		controllerPausedSkipped.WithLabelValues(GetKindForObject(obj, false)).Inc()
+		return false
+	}
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return filter(e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return filter(e.ObjectNew) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return filter(e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return filter(e.Object) },
+	}
+}
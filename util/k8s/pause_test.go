@@ -0,0 +1,72 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/util/k8s"
+)
+
+func newPausableConfigMap(annotations map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "example", Annotations: annotations}}
+}
+
+func getPausedSkipped(t *testing.T, kind string) float64 {
+	families, err := ctrlmetrics.Registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "kubevela_"+k8s.ControllerPausedSkippedKey {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			if m.GetLabel()[0].GetValue() == kind {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func TestIsPaused(t *testing.T) {
+	require.False(t, k8s.IsPaused(newPausableConfigMap(nil), ""))
+	require.True(t, k8s.IsPaused(newPausableConfigMap(map[string]string{k8s.PauseAnnotation: "true"}), ""))
+	require.False(t, k8s.IsPaused(newPausableConfigMap(map[string]string{k8s.PauseAnnotation: "false"}), ""))
+	require.False(t, k8s.IsPaused(newPausableConfigMap(map[string]string{k8s.PauseAnnotation: "true"}), "example.com/pause"))
+	require.True(t, k8s.IsPaused(newPausableConfigMap(map[string]string{"example.com/pause": "true"}), "example.com/pause"))
+}
+
+func TestPausedPredicate(t *testing.T) {
+	p := k8s.PausedPredicate("example.com/pause")
+	paused := newPausableConfigMap(map[string]string{"example.com/pause": "true"})
+	unpaused := newPausableConfigMap(nil)
+	before := getPausedSkipped(t, "ConfigMap")
+
+	require.True(t, p.Create(event.CreateEvent{Object: unpaused}))
+	require.False(t, p.Create(event.CreateEvent{Object: paused}))
+	require.False(t, p.Update(event.UpdateEvent{ObjectOld: unpaused, ObjectNew: paused}))
+	require.True(t, p.Update(event.UpdateEvent{ObjectOld: paused, ObjectNew: unpaused}))
+	require.False(t, p.Delete(event.DeleteEvent{Object: paused}))
+	require.True(t, p.Generic(event.GenericEvent{Object: unpaused}))
+	require.Equal(t, 3.0, getPausedSkipped(t, "ConfigMap")-before)
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubevela/pkg/util/compression"
+)
+
+const (
+	// SecretDataEncodingAnnotation the annotation recording the encoding of
+	// each secret data key in JSON, keyed by the data key. A single JSON
+	// annotation is used as the data keys might not fit in annotation names.
+	SecretDataEncodingAnnotation = "secret.kubevela.io/data-encoding"
+)
+
+// SecretDataEncoding the encoding of the secret data key
+type SecretDataEncoding struct {
+	// Compression the compression type of the data
+	Compression compression.Type `json:"compression,omitempty"`
+	// Encrypted whether the data is encrypted
+	Encrypted bool `json:"encrypted,omitempty"`
+}
+
+// Encryptor encrypts and decrypts the secret data, which can be used to hook
+// envelope encryption, like encrypting with a data key managed by KMS
+type Encryptor interface {
+	Encrypt(ctx context.Context, plaintext []byte) ([]byte, error)
+	Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error)
+}
+
+// SecretDataOptions the options for storing and loading the secret data
+type SecretDataOptions struct {
+	// Compression the compression type for storing the data. Uncompressed by default.
+	Compression compression.Type
+	// Encryptor encrypts the data after compression. No encryption by default.
+	// It is required for loading encrypted data.
+	Encryptor Encryptor
+}
+
+// StoreSecretData stores the data under the key of the secret, which is
+// created if not exists. The raw data is compressed and then encrypted
+// according to the options, and the encoding is recorded in the
+// SecretDataEncodingAnnotation of the secret, so that LoadSecretData can
+// reverse it.
+func StoreSecretData(ctx context.Context, c client.Client, secretKey types.NamespacedName, key string, data []byte, opts *SecretDataOptions) error {
+	if opts == nil {
+		opts = &SecretDataOptions{}
+	}
+	value := data
+	if opts.Compression != compression.Uncompressed {
+		compressed, err := compression.Compress(opts.Compression, data)
+		if err != nil {
+			return err
+		}
+		value = compressed
+	}
+	if opts.Encryptor != nil {
+		encrypted, err := opts.Encryptor.Encrypt(ctx, value)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt secret data %s: %w", key, err)
+		}
+		value = encrypted
+	}
+
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, secretKey, secret)
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	creating := kerrors.IsNotFound(err)
+	if creating {
+		secret.SetNamespace(secretKey.Namespace)
+		secret.SetName(secretKey.Name)
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[key] = value
+	encodings, err := getSecretDataEncodings(secret)
+	if err != nil {
+		return err
+	}
+	encoding := SecretDataEncoding{Compression: opts.Compression, Encrypted: opts.Encryptor != nil}
+	if encoding == (SecretDataEncoding{}) {
+		delete(encodings, key)
+	} else {
+		encodings[key] = encoding
+	}
+	annotations := secret.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if len(encodings) == 0 {
+		delete(annotations, SecretDataEncodingAnnotation)
+	} else {
+		bs, err := json.Marshal(encodings)
+		if err != nil {
+			return err
+		}
+		annotations[SecretDataEncodingAnnotation] = string(bs)
+	}
+	secret.SetAnnotations(annotations)
+	if creating {
+		return c.Create(ctx, secret)
+	}
+	return c.Update(ctx, secret)
+}
+
+// LoadSecretData loads the data under the key of the secret stored by
+// StoreSecretData. The data is decrypted and decompressed according to the
+// SecretDataEncodingAnnotation of the secret. Only the Encryptor in the
+// options is used.
+func LoadSecretData(ctx context.Context, c client.Client, secretKey types.NamespacedName, key string, opts *SecretDataOptions) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, secretKey, secret); err != nil {
+		return nil, err
+	}
+	value, found := secret.Data[key]
+	if !found {
+		return nil, fmt.Errorf("key %s not found in secret %s", key, secretKey)
+	}
+	encodings, err := getSecretDataEncodings(secret)
+	if err != nil {
+		return nil, err
+	}
+	encoding := encodings[key]
+	if encoding.Encrypted {
+		if opts == nil || opts.Encryptor == nil {
+			return nil, fmt.Errorf("secret data %s is encrypted but no encryptor is provided", key)
+		}
+		decrypted, err := opts.Encryptor.Decrypt(ctx, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt secret data %s: %w", key, err)
+		}
+		value = decrypted
+	}
+	if encoding.Compression != compression.Uncompressed {
+		if value, err = compression.Decompress(encoding.Compression, value); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// getSecretDataEncodings parses the SecretDataEncodingAnnotation of the secret
+func getSecretDataEncodings(secret *corev1.Secret) (map[string]SecretDataEncoding, error) {
+	encodings := map[string]SecretDataEncoding{}
+	if raw, found := secret.GetAnnotations()[SecretDataEncodingAnnotation]; found {
+		if err := json.Unmarshal([]byte(raw), &encodings); err != nil {
+			return nil, fmt.Errorf("invalid annotation %s of secret %s: %w", SecretDataEncodingAnnotation, client.ObjectKeyFromObject(secret), err)
+		}
+	}
+	return encodings, nil
+}
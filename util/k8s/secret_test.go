@@ -0,0 +1,156 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kubevela/pkg/util/compression"
+	"github.com/kubevela/pkg/util/k8s"
+)
+
+// xorEncryptor a stub encryptor flipping all the bits
+type xorEncryptor struct{}
+
+func (xorEncryptor) Encrypt(_ context.Context, plaintext []byte) ([]byte, error) {
+	return xor(plaintext), nil
+}
+
+func (xorEncryptor) Decrypt(_ context.Context, ciphertext []byte) ([]byte, error) {
+	return xor(ciphertext), nil
+}
+
+func xor(data []byte) []byte {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[i] = b ^ 0xff
+	}
+	return out
+}
+
+func TestStoreAndLoadSecretData(t *testing.T) {
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: "default", Name: "blob"}
+	data := bytes.Repeat([]byte("kubevela"), 64)
+	testcases := map[string]struct {
+		dataKey  string
+		opts     *k8s.SecretDataOptions
+		encoding *k8s.SecretDataEncoding
+	}{
+		"plain": {dataKey: "data"},
+		"gzip": {
+			dataKey:  "data",
+			opts:     &k8s.SecretDataOptions{Compression: compression.Gzip},
+			encoding: &k8s.SecretDataEncoding{Compression: compression.Gzip},
+		},
+		"encrypted": {
+			dataKey:  "data",
+			opts:     &k8s.SecretDataOptions{Encryptor: xorEncryptor{}},
+			encoding: &k8s.SecretDataEncoding{Encrypted: true},
+		},
+		"zstd-encrypted": {
+			dataKey:  "data",
+			opts:     &k8s.SecretDataOptions{Compression: compression.Zstd, Encryptor: xorEncryptor{}},
+			encoding: &k8s.SecretDataEncoding{Compression: compression.Zstd, Encrypted: true},
+		},
+		"long-key": {
+			dataKey:  strings.Repeat("k", 100) + "-",
+			opts:     &k8s.SecretDataOptions{Compression: compression.Gzip},
+			encoding: &k8s.SecretDataEncoding{Compression: compression.Gzip},
+		},
+	}
+	for name, tt := range testcases {
+		t.Run(name, func(t *testing.T) {
+			cli := fake.NewClientBuilder().Build()
+			require.NoError(t, k8s.StoreSecretData(ctx, cli, key, tt.dataKey, data, tt.opts))
+			// store twice to cover updating the existing secret
+			require.NoError(t, k8s.StoreSecretData(ctx, cli, key, tt.dataKey, data, tt.opts))
+
+			secret := &corev1.Secret{}
+			require.NoError(t, cli.Get(ctx, key, secret))
+			raw, found := secret.GetAnnotations()[k8s.SecretDataEncodingAnnotation]
+			require.Equal(t, tt.encoding != nil, found)
+			if tt.encoding != nil {
+				encodings := map[string]k8s.SecretDataEncoding{}
+				require.NoError(t, json.Unmarshal([]byte(raw), &encodings))
+				require.Equal(t, map[string]k8s.SecretDataEncoding{tt.dataKey: *tt.encoding}, encodings)
+			}
+			if tt.opts != nil {
+				require.NotEqual(t, data, secret.Data[tt.dataKey])
+			}
+
+			loaded, err := k8s.LoadSecretData(ctx, cli, key, tt.dataKey, tt.opts)
+			require.NoError(t, err)
+			require.Equal(t, data, loaded)
+		})
+	}
+}
+
+func TestStoreSecretDataRawCompression(t *testing.T) {
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: "default", Name: "blob"}
+	data := bytes.Repeat([]byte("kubevela"), 64)
+	cli := fake.NewClientBuilder().Build()
+	require.NoError(t, k8s.StoreSecretData(ctx, cli, key, "data", data, &k8s.SecretDataOptions{Compression: compression.Gzip}))
+
+	secret := &corev1.Secret{}
+	require.NoError(t, cli.Get(ctx, key, secret))
+	reader, err := gzip.NewReader(bytes.NewReader(secret.Data["data"]))
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, data, decompressed)
+}
+
+func TestLoadSecretDataErrors(t *testing.T) {
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: "default", Name: "blob"}
+	cli := fake.NewClientBuilder().Build()
+	_, err := k8s.LoadSecretData(ctx, cli, key, "data", nil)
+	require.Error(t, err)
+
+	require.NoError(t, k8s.StoreSecretData(ctx, cli, key, "data", []byte("val"), &k8s.SecretDataOptions{Encryptor: xorEncryptor{}}))
+	_, err = k8s.LoadSecretData(ctx, cli, key, "other", nil)
+	require.ErrorContains(t, err, "not found")
+	_, err = k8s.LoadSecretData(ctx, cli, key, "data", nil)
+	require.ErrorContains(t, err, "no encryptor")
+
+	// switching back to plain storage drops the annotations
+	require.NoError(t, k8s.StoreSecretData(ctx, cli, key, "data", []byte("val"), nil))
+	loaded, err := k8s.LoadSecretData(ctx, cli, key, "data", nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte("val"), loaded)
+	secret := &corev1.Secret{}
+	require.NoError(t, cli.Get(ctx, key, secret))
+	require.NotContains(t, secret.GetAnnotations(), k8s.SecretDataEncodingAnnotation)
+
+	secret.SetAnnotations(map[string]string{k8s.SecretDataEncodingAnnotation: "{"})
+	require.NoError(t, cli.Update(ctx, secret))
+	_, err = k8s.LoadSecretData(ctx, cli, key, "data", nil)
+	require.ErrorContains(t, err, "invalid annotation")
+}
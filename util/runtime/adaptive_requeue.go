@@ -0,0 +1,103 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var (
+	// AdaptiveRequeueSensitivity how much the requeue delay is scaled per
+	// unit of error ratio deviating from AdaptiveRequeueTargetErrorRatio
+	AdaptiveRequeueSensitivity = 4.0
+	// AdaptiveRequeueTargetErrorRatio the error ratio at which the requeue
+	// delay is kept unchanged. Higher error ratios stretch the delay, and
+	// lower ones tighten it.
+	AdaptiveRequeueTargetErrorRatio = 0.1
+	// AdaptiveRequeueMinFactor the min factor applied to the requeue delay
+	AdaptiveRequeueMinFactor = 0.5
+	// AdaptiveRequeueMaxFactor the max factor applied to the requeue delay
+	AdaptiveRequeueMaxFactor = 10.0
+	// AdaptiveRequeueWindow the time window for computing the recent error
+	// ratio of the controller
+	AdaptiveRequeueWindow = time.Minute
+)
+
+// errorRatioWindow computes the error ratio of the reconciles since the
+// window begins. The last ratio is kept if no reconcile happens since then.
+type errorRatioWindow struct {
+	mu    sync.Mutex
+	begin time.Time
+	base  ReconcileStatsSnapshot
+	ratio float64
+}
+
+var controllerErrorRatioWindows sync.Map
+
+// recentErrorRatio returns the error ratio of the recent reconciles of the
+// controller. Before the first window of the controller, the ratio of all the
+// reconciles is used.
+func recentErrorRatio(controller string, now time.Time) float64 {
+	current := ControllerReconcileStats(controller)
+	value, found := controllerErrorRatioWindows.Load(controller)
+	if !found {
+		w := &errorRatioWindow{begin: now, base: current}
+		if current.Total > 0 {
+			w.ratio = float64(current.Errors) / float64(current.Total)
+		}
+		value, _ = controllerErrorRatioWindows.LoadOrStore(controller, w)
+	}
+	w := value.(*errorRatioWindow)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if total := current.Total - w.base.Total; total > 0 {
+		w.ratio = float64(current.Errors-w.base.Errors) / float64(total)
+	}
+	if now.Sub(w.begin) >= AdaptiveRequeueWindow {
+		w.begin, w.base = now, current
+	}
+	return w.ratio
+}
+
+// AdaptiveRequeue adjusts the RequeueAfter of the result according to the
+// recent error ratio of the controller set by WithController, which is
+// monitored by MonitorReconcile. The delay is scaled by
+// 1 + AdaptiveRequeueSensitivity * (ratio - AdaptiveRequeueTargetErrorRatio),
+// bounded by AdaptiveRequeueMinFactor and AdaptiveRequeueMaxFactor, so that
+// retries back off when errors spike and speed up when healthy. Results
+// without RequeueAfter or without the controller in the context are
+// returned unchanged.
+func AdaptiveRequeue(ctx context.Context, baseResult reconcile.Result) reconcile.Result {
+	controller, ok := ControllerFrom(ctx)
+	if !ok || baseResult.RequeueAfter <= 0 {
+		return baseResult
+	}
+	ratio := recentErrorRatio(controller, time.Now())
+	factor := 1 + AdaptiveRequeueSensitivity*(ratio-AdaptiveRequeueTargetErrorRatio)
+	if factor < AdaptiveRequeueMinFactor {
+		factor = AdaptiveRequeueMinFactor
+	}
+	if factor > AdaptiveRequeueMaxFactor {
+		factor = AdaptiveRequeueMaxFactor
+	}
+	baseResult.RequeueAfter = time.Duration(float64(baseResult.RequeueAfter) * factor)
+	return baseResult
+}
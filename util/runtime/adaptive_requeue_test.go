@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestAdaptiveRequeue(t *testing.T) {
+	r := require.New(t)
+	feed := func(controller string, errors int, successes int) {
+		reconciler := MonitorReconcile(controller, reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+			if errors > 0 {
+				errors--
+				return reconcile.Result{}, fmt.Errorf("failed")
+			}
+			return reconcile.Result{}, nil
+		}))
+		for i := errors + successes; i > 0; i-- {
+			_, _ = reconciler.Reconcile(context.Background(), reconcile.Request{})
+		}
+	}
+	base := reconcile.Result{RequeueAfter: 10 * time.Second}
+
+	unhealthy, healthy := uniqueController("adaptive-unhealthy"), uniqueController("adaptive-healthy")
+
+	// high error ratio stretches the delay
+	feed(unhealthy, 8, 2)
+	ctx := WithController(context.Background(), unhealthy)
+	r.Equal(38*time.Second, AdaptiveRequeue(ctx, base).RequeueAfter)
+
+	// low error ratio tightens the delay
+	feed(healthy, 0, 10)
+	ctx = WithController(context.Background(), healthy)
+	r.Equal(6*time.Second, AdaptiveRequeue(ctx, base).RequeueAfter)
+
+	// the ratio follows the reconciles in the current window
+	feed(healthy, 10, 0)
+	r.Equal(46*time.Second, AdaptiveRequeue(ctx, base).RequeueAfter)
+
+	defer func(sensitivity float64) { AdaptiveRequeueSensitivity = sensitivity }(AdaptiveRequeueSensitivity)
+	AdaptiveRequeueSensitivity = 20
+	r.Equal(100*time.Second, AdaptiveRequeue(ctx, base).RequeueAfter)
+
+	// results without delay or controller are unchanged
+	r.Equal(reconcile.Result{Requeue: true}, AdaptiveRequeue(ctx, reconcile.Result{Requeue: true}))
+	r.Equal(base, AdaptiveRequeue(context.Background(), base))
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	goruntime "runtime"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+)
+
+const (
+	// ControllerReconcileAllocBytesKey metrics key for recording the bytes
+	// allocated during controller reconciles
+	ControllerReconcileAllocBytesKey = "controller_reconcile_alloc_bytes"
+)
+
+var (
+	// controllerReconcileAllocBytes the reconcile allocation metrics
+	controllerReconcileAllocBytes = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerReconcileAllocBytesKey,
+			Help:      "bytes allocated during sampled reconciles for kubevela controllers, debug only",
+			Buckets:   prometheus.ExponentialBuckets(1024, 4, 10),
+		}, []string{"controller"})
+)
+
+var (
+	// ReconcileAllocDebug enables recording the allocated bytes of sampled
+	// reconciles by MonitorReconcile.
+	// DEBUG ONLY: reading runtime.MemStats stops the world, which slows down
+	// the whole process. Besides, the allocations are counted process-wide,
+	// so with concurrent reconciles the samples are only an approximation.
+	ReconcileAllocDebug = false
+	// ReconcileAllocSampleInterval only one in every interval reconciles is
+	// sampled when ReconcileAllocDebug is enabled
+	ReconcileAllocSampleInterval int64 = 100
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerReconcileAllocBytes)
+}
+
+// monitorAlloc creates a callback to call when the n-th reconcile ends
+// It reports the allocated bytes during the reconcile if the reconcile is
+// sampled in debug mode.
+func monitorAlloc(controller string, n int64) func() {
+	if !ReconcileAllocDebug || ReconcileAllocSampleInterval <= 0 || n%ReconcileAllocSampleInterval != 0 {
+		return func() {}
+	}
+	stats := &goruntime.MemStats{}
+	goruntime.ReadMemStats(stats)
+	begin := stats.TotalAlloc
+	return func() {
+		goruntime.ReadMemStats(stats)
+		controllerReconcileAllocBytes.WithLabelValues(controller).Observe(float64(stats.TotalAlloc - begin))
+	}
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestMonitorAlloc(t *testing.T) {
+	r := require.New(t)
+	controller := uniqueController("alloc")
+	reconciler := MonitorReconcile(controller, reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		_ = make([]byte, 1<<20)
+		return reconcile.Result{}, nil
+	}))
+	r.False(ReconcileAllocDebug)
+	series := testutil.CollectAndCount(controllerReconcileAllocBytes)
+	for i := 0; i < int(ReconcileAllocSampleInterval); i++ {
+		_, _ = reconciler.Reconcile(context.Background(), reconcile.Request{})
+	}
+	r.Equal(series, testutil.CollectAndCount(controllerReconcileAllocBytes))
+
+	defer func(debug bool, interval int64) {
+		ReconcileAllocDebug, ReconcileAllocSampleInterval = debug, interval
+	}(ReconcileAllocDebug, ReconcileAllocSampleInterval)
+	ReconcileAllocDebug, ReconcileAllocSampleInterval = true, 2
+	for i := 0; i < 4; i++ {
+		_, _ = reconciler.Reconcile(context.Background(), reconcile.Request{})
+	}
+	r.Equal(series+1, testutil.CollectAndCount(controllerReconcileAllocBytes))
+	m := &dto.Metric{}
+	r.NoError(controllerReconcileAllocBytes.WithLabelValues(controller).(prometheus.Histogram).Write(m))
+	r.Equal(uint64(2), m.Histogram.GetSampleCount())
+}
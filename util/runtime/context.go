@@ -0,0 +1,41 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import "context"
+
+type contextKey int
+
+const (
+	// traceContextKey is the context key for reconcile trace
+	traceContextKey contextKey = iota
+	// controllerContextKey is the context key for the reconciling controller
+	controllerContextKey
+	// leaderContextKey is the context key for overriding the leadership
+	leaderContextKey
+)
+
+// WithController returns a copy of parent in which the controller value is set
+func WithController(parent context.Context, controller string) context.Context {
+	return context.WithValue(parent, controllerContextKey, controller)
+}
+
+// ControllerFrom returns the value of the controller key on the ctx
+func ControllerFrom(ctx context.Context) (string, bool) {
+	controller, ok := ctx.Value(controllerContextKey).(string)
+	return controller, ok
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"hash/maphash"
+	"math"
+	"math/bits"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+)
+
+const (
+	// ControllerDistinctObjectsKey metrics key for recording the estimated
+	// number of distinct objects reconciled within the window
+	ControllerDistinctObjectsKey = "controller_distinct_objects"
+
+	// hllPrecision the number of bits for indexing the registers of the
+	// HyperLogLog sketch, which gives 4096 registers and ~1.6% standard error
+	hllPrecision = 12
+	hllRegisters = 1 << hllPrecision
+)
+
+// distinctObjectsCollector publishes the estimates of the completed windows
+// when collected, so that idle controllers report 0 instead of the estimate
+// of the last busy window
+type distinctObjectsCollector struct {
+	*prometheus.GaugeVec
+	counters sync.Map
+}
+
+// Collect .
+func (in *distinctObjectsCollector) Collect(ch chan<- prometheus.Metric) {
+	now := time.Now()
+	in.counters.Range(func(_, value any) bool {
+		value.(*distinctObjectsCounter).flush(now)
+		return true
+	})
+	in.GaugeVec.Collect(ch)
+}
+
+var (
+	// controllerDistinctObjects the distinct objects metrics
+	controllerDistinctObjects = &distinctObjectsCollector{GaugeVec: prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerDistinctObjectsKey,
+			Help:      "estimated number of distinct objects reconciled by kubevela controllers in the last complete window",
+		}, []string{"controller"})}
+)
+
+var (
+	// DistinctObjectsWindow the time window for estimating the distinct
+	// objects reconciled by MonitorReconcile. The estimate of the last
+	// complete window is published, in constant memory.
+	DistinctObjectsWindow = time.Minute
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerDistinctObjects)
+}
+
+// hyperLogLog estimates the number of distinct keys in constant memory
+type hyperLogLog struct {
+	seed      maphash.Seed
+	registers [hllRegisters]uint8
+}
+
+func (in *hyperLogLog) add(key string) {
+	hash := maphash.String(in.seed, key)
+	idx := hash >> (64 - hllPrecision)
+	rank := uint8(bits.LeadingZeros64(hash<<hllPrecision|1<<(hllPrecision-1)) + 1)
+	if rank > in.registers[idx] {
+		in.registers[idx] = rank
+	}
+}
+
+func (in *hyperLogLog) estimate() float64 {
+	sum, zeros := 0.0, 0
+	for _, r := range in.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	m := float64(hllRegisters)
+	e := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if e <= 2.5*m && zeros > 0 {
+		// linear counting for small cardinalities
+		e = m * math.Log(m/float64(zeros))
+	}
+	return e
+}
+
+func (in *hyperLogLog) reset() {
+	in.registers = [hllRegisters]uint8{}
+}
+
+// distinctObjectsCounter counts the distinct reconciled objects in fixed
+// windows and publishes the estimate when the window completes
+type distinctObjectsCounter struct {
+	mu         sync.Mutex
+	controller string
+	begin      time.Time
+	sketch     *hyperLogLog
+}
+
+func newDistinctObjectsCounter(controller string) *distinctObjectsCounter {
+	counter := &distinctObjectsCounter{controller: controller, sketch: &hyperLogLog{seed: maphash.MakeSeed()}}
+	controllerDistinctObjects.counters.Store(controller, counter)
+	return counter
+}
+
+// observe records the reconcile of the object. If the current window has
+// completed, its estimate is published before starting the next window.
+func (in *distinctObjectsCounter) observe(key types.NamespacedName, now time.Time) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.rotate(now)
+	in.sketch.add(key.String())
+}
+
+// flush publishes the estimate if the current window has completed
+func (in *distinctObjectsCounter) flush(now time.Time) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.rotate(now)
+}
+
+// rotate publishes the estimate and starts the next window if the current
+// window has completed. If the next window has also completed without any
+// reconcile, 0 is published.
+func (in *distinctObjectsCounter) rotate(now time.Time) {
+	if in.begin.IsZero() {
+		in.begin = now
+	}
+	elapsed := now.Sub(in.begin)
+	if elapsed <= DistinctObjectsWindow {
+		return
+	}
+	estimate := math.Round(in.sketch.estimate())
+	if elapsed > 2*DistinctObjectsWindow {
+		estimate = 0
+	}
+	controllerDistinctObjects.WithLabelValues(in.controller).Set(estimate)
+	in.sketch.reset()
+	in.begin = now
+}
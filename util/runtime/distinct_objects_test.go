@@ -0,0 +1,62 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"fmt"
+	"hash/maphash"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	for _, n := range []int{100, 10000, 100000} {
+		t.Run(fmt.Sprintf("%d", n), func(t *testing.T) {
+			sketch := &hyperLogLog{seed: maphash.MakeSeed()}
+			for round := 0; round < 3; round++ {
+				for i := 0; i < n; i++ {
+					sketch.add(fmt.Sprintf("default/object-%d", i))
+				}
+			}
+			require.InEpsilon(t, float64(n), sketch.estimate(), 0.08)
+		})
+	}
+}
+
+func TestDistinctObjectsCounter(t *testing.T) {
+	r := require.New(t)
+	controllerDistinctObjects.DeleteLabelValues("distinct")
+	counter := newDistinctObjectsCounter("distinct")
+	now := time.Now()
+	for i := 0; i < 1000; i++ {
+		counter.observe(types.NamespacedName{Namespace: "default", Name: fmt.Sprintf("object-%d", i%200)}, now)
+	}
+	// the estimate is published when the window completes
+	r.Equal(0.0, testutil.ToFloat64(controllerDistinctObjects.WithLabelValues("distinct")))
+	counter.observe(types.NamespacedName{Namespace: "default", Name: "object-0"}, now.Add(DistinctObjectsWindow+time.Second))
+	r.InEpsilon(200.0, testutil.ToFloat64(controllerDistinctObjects.WithLabelValues("distinct")), 0.05)
+
+	// idle controllers publish 0 once a window completes without reconciles
+	counter.flush(now.Add(2*DistinctObjectsWindow + 2*time.Second))
+	r.Equal(1.0, testutil.ToFloat64(controllerDistinctObjects.WithLabelValues("distinct")))
+	counter.flush(now.Add(3*DistinctObjectsWindow + 3*time.Second))
+	r.Equal(0.0, testutil.ToFloat64(controllerDistinctObjects.WithLabelValues("distinct")))
+}
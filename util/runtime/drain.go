@@ -0,0 +1,79 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+)
+
+const (
+	// ControllerDrainTimeKey metrics key for recording the time cost of
+	// draining in-flight reconciles on shutdown
+	ControllerDrainTimeKey = "controller_drain_time_seconds"
+)
+
+var (
+	// controllerDrainTime the drain time metrics
+	controllerDrainTime = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerDrainTimeKey,
+			Help:      "time cost of draining in-flight reconciles for kubevela controllers",
+			Buckets:   metrics.FineGrainedBuckets,
+		})
+)
+
+var (
+	// DrainPollInterval the interval for checking in-flight reconciles when
+	// draining
+	DrainPollInterval = 100 * time.Millisecond
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerDrainTime)
+}
+
+// DrainReconciles waits for the in-flight reconciles of all controllers
+// monitored by MonitorReconcile to finish. It returns an error if reconciles
+// are still running after the timeout or the context is done. The time cost
+// of draining is recorded in metrics either way.
+func DrainReconciles(ctx context.Context, timeout time.Duration) error {
+	begin := time.Now()
+	defer func() { controllerDrainTime.Observe(time.Since(begin).Seconds()) }()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(DrainPollInterval)
+	defer ticker.Stop()
+	for {
+		inFlight := ReconcileStats().InFlight
+		if inFlight <= 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d reconciles still in flight after draining for %s: %w", inFlight, time.Since(begin), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
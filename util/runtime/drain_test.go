@@ -0,0 +1,56 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kubevela/pkg/util/runtime"
+)
+
+func TestDrainReconciles(t *testing.T) {
+	r := require.New(t)
+	defer func(interval time.Duration) { runtime.DrainPollInterval = interval }(runtime.DrainPollInterval)
+	runtime.DrainPollInterval = 5 * time.Millisecond
+	ctx := context.Background()
+	r.NoError(runtime.DrainReconciles(ctx, time.Second))
+
+	release := make(chan struct{})
+	reconciler := runtime.MonitorReconcile("drain", reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		<-release
+		return reconcile.Result{}, nil
+	}))
+	done := make(chan struct{})
+	go func() {
+		_, _ = reconciler.Reconcile(ctx, reconcile.Request{})
+		close(done)
+	}()
+	r.Eventually(func() bool { return runtime.ControllerReconcileStats("drain").InFlight == 1 }, time.Second, 5*time.Millisecond)
+
+	err := runtime.DrainReconciles(ctx, 50*time.Millisecond)
+	r.Error(err)
+	r.Contains(err.Error(), "1 reconciles still in flight")
+
+	time.AfterFunc(20*time.Millisecond, func() { close(release) })
+	r.NoError(runtime.DrainReconciles(ctx, time.Second))
+	<-done
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+)
+
+const (
+	// ControllerReconcileErrorKey metrics key for counting the reconcile
+	// errors by category
+	ControllerReconcileErrorKey = "controller_reconcile_error_total"
+)
+
+var (
+	// controllerReconcileError the reconcile error metrics
+	controllerReconcileError = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerReconcileErrorKey,
+			Help:      "number of reconcile errors for kubevela controllers by category",
+		}, []string{"controller", "category"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerReconcileError)
+}
+
+// ErrorCategory the category of errors, which tells whether retrying helps
+type ErrorCategory string
+
+const (
+	// ErrorCategoryTransient errors which are expected to disappear on retry,
+	// such as conflicts, throttling and timeouts
+	ErrorCategoryTransient ErrorCategory = "transient"
+	// ErrorCategoryPermanent errors which persist until the object or the
+	// environment is fixed, such as validation failures
+	ErrorCategoryPermanent ErrorCategory = "permanent"
+	// ErrorCategoryUnknown errors which cannot be categorized
+	ErrorCategoryUnknown ErrorCategory = "unknown"
+)
+
+// ErrorClassifier returns the category of the error, or false if the error
+// is not recognized by the classifier
+type ErrorClassifier func(err error) (ErrorCategory, bool)
+
+var (
+	// MaxCustomErrorCategories the max number of distinct categories returned
+	// by the registered classifiers to be recorded in the reconcile error
+	// metrics. Categories beyond the limit are recorded as unknown.
+	MaxCustomErrorCategories = 10
+)
+
+var (
+	errorClassifiersMu sync.RWMutex
+	errorClassifiers   []ErrorClassifier
+
+	customErrorCategoriesMu sync.Mutex
+	customErrorCategories   = map[ErrorCategory]struct{}{}
+)
+
+// RegisterErrorClassifier registers the classifier used by ClassifyError.
+// Registered classifiers are tried in order before the built-in rules.
+func RegisterErrorClassifier(classifier ErrorClassifier) {
+	errorClassifiersMu.Lock()
+	defer errorClassifiersMu.Unlock()
+	errorClassifiers = append(errorClassifiers, classifier)
+}
+
+// ClassifyError returns the category of the error, by which MonitorReconcile
+// counts the reconcile errors. The registered classifiers are tried first,
+// then the apiserver errors are categorized by their reasons.
+func ClassifyError(err error) ErrorCategory {
+	if err == nil {
+		return ""
+	}
+	errorClassifiersMu.RLock()
+	classifiers := errorClassifiers
+	errorClassifiersMu.RUnlock()
+	for _, classifier := range classifiers {
+		if category, ok := classifier(err); ok {
+			return category
+		}
+	}
+	switch {
+	case kerrors.IsConflict(err), kerrors.IsTooManyRequests(err), kerrors.IsServerTimeout(err),
+		kerrors.IsTimeout(err), kerrors.IsServiceUnavailable(err), kerrors.IsInternalError(err),
+		errors.Is(err, context.DeadlineExceeded):
+		return ErrorCategoryTransient
+	case kerrors.IsInvalid(err), kerrors.IsBadRequest(err), kerrors.IsForbidden(err),
+		kerrors.IsUnauthorized(err), kerrors.IsMethodNotSupported(err), kerrors.IsNotAcceptable(err),
+		kerrors.IsUnsupportedMediaType(err), kerrors.IsRequestEntityTooLargeError(err):
+		return ErrorCategoryPermanent
+	default:
+		return ErrorCategoryUnknown
+	}
+}
+
+// errorCategoryLabel returns the metrics label of the category, which bounds
+// the custom categories by MaxCustomErrorCategories
+func errorCategoryLabel(category ErrorCategory) string {
+	switch category {
+	case ErrorCategoryTransient, ErrorCategoryPermanent, ErrorCategoryUnknown:
+		return string(category)
+	}
+	customErrorCategoriesMu.Lock()
+	defer customErrorCategoriesMu.Unlock()
+	if _, found := customErrorCategories[category]; !found {
+		if len(customErrorCategories) >= MaxCustomErrorCategories {
+			return string(ErrorCategoryUnknown)
+		}
+		customErrorCategories[category] = struct{}{}
+	}
+	return string(category)
+}
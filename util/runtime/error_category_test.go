@@ -0,0 +1,81 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var errQuotaExceeded = errors.New("quota exceeded")
+
+var errRandom = errors.New("random")
+
+func TestMonitorReconcileErrorCategory(t *testing.T) {
+	r := require.New(t)
+	defer func(classifiers []ErrorClassifier, categories map[ErrorCategory]struct{}, limit int) {
+		errorClassifiers, customErrorCategories, MaxCustomErrorCategories = classifiers, categories, limit
+	}(errorClassifiers, customErrorCategories, MaxCustomErrorCategories)
+	customErrorCategories, MaxCustomErrorCategories = map[ErrorCategory]struct{}{}, 1
+	RegisterErrorClassifier(func(err error) (ErrorCategory, bool) {
+		if errors.Is(err, errQuotaExceeded) {
+			return "quota", true
+		}
+		if errors.Is(err, errRandom) {
+			return ErrorCategory(err.Error()), true
+		}
+		return "", false
+	})
+	gr := schema.GroupResource{Resource: "configmaps"}
+	errs := map[string]error{
+		"conflict": kerrors.NewConflict(gr, "example", fmt.Errorf("conflict")),
+		"throttle": kerrors.NewTooManyRequests("throttled", 1),
+		"invalid":  kerrors.NewInvalid(schema.GroupKind{Kind: "ConfigMap"}, "example", field.ErrorList{field.Required(field.NewPath("data"), "")}),
+		"quota":    fmt.Errorf("failed to create: %w", errQuotaExceeded),
+		"unknown":  fmt.Errorf("failed"),
+		"random":   fmt.Errorf("%w-1", errRandom),
+	}
+	controller := uniqueController("category")
+	reconciler := MonitorReconcile(controller, reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		return reconcile.Result{}, errs[req.Name]
+	}))
+	// the quota error is reconciled before the random one taking up the limit
+	for _, name := range []string{"conflict", "throttle", "invalid", "quota", "random", "unknown"} {
+		req := reconcile.Request{}
+		req.Name = name
+		_, err := reconciler.Reconcile(context.Background(), req)
+		r.Error(err)
+	}
+	count := func(category ErrorCategory) float64 {
+		return testutil.ToFloat64(controllerReconcileError.WithLabelValues(controller, string(category)))
+	}
+	r.Equal(2.0, count(ErrorCategoryTransient))
+	r.Equal(1.0, count(ErrorCategoryPermanent))
+	r.Equal(1.0, count("quota"))
+	// custom categories beyond the limit are recorded as unknown
+	r.Equal(2.0, count(ErrorCategoryUnknown))
+	r.Equal(0.0, count("random-1"))
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+)
+
+const (
+	// ControllerReconcileAggregatedErrorsKey metrics key for recording the
+	// number of errors aggregated in each reconcile
+	ControllerReconcileAggregatedErrorsKey = "controller_reconcile_aggregated_errors"
+)
+
+var (
+	// controllerReconcileAggregatedErrors the aggregated errors metrics
+	controllerReconcileAggregatedErrors = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerReconcileAggregatedErrorsKey,
+			Help:      "number of errors aggregated in each reconcile for kubevela controllers",
+			Buckets:   []float64{0, 1, 2, 5, 10, 20, 50, 100},
+		}, []string{"controller"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerReconcileAggregatedErrors)
+}
+
+// ErrorGroup collects the errors during the reconcile, so that they can be
+// reported together instead of failing at the first one. It is safe for
+// concurrent use.
+type ErrorGroup struct {
+	controller string
+	mu         sync.Mutex
+	errs       []error
+}
+
+// NewErrorGroup creates an ErrorGroup for the reconcile of the controller set
+// by WithController in the context
+func NewErrorGroup(ctx context.Context) *ErrorGroup {
+	controller, _ := ControllerFrom(ctx)
+	return &ErrorGroup{controller: controller}
+}
+
+// Add records the error with the context given as key-value pairs, such as
+// Add(err, "kind", "ConfigMap", "object", "default/example"). Nil errors are
+// ignored.
+func (in *ErrorGroup) Add(err error, keysAndValues ...interface{}) {
+	if err == nil {
+		return
+	}
+	if len(keysAndValues) > 0 {
+		pairs := make([]string, 0, (len(keysAndValues)+1)/2)
+		for i := 0; i < len(keysAndValues); i += 2 {
+			if i+1 < len(keysAndValues) {
+				pairs = append(pairs, fmt.Sprintf("%v=%v", keysAndValues[i], keysAndValues[i+1]))
+			} else {
+				pairs = append(pairs, fmt.Sprintf("%v", keysAndValues[i]))
+			}
+		}
+		err = fmt.Errorf("%w (%s)", err, strings.Join(pairs, ", "))
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.errs = append(in.errs, err)
+}
+
+// Len returns the number of recorded errors
+func (in *ErrorGroup) Len() int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return len(in.errs)
+}
+
+// Err returns the aggregated error of all the recorded errors, nil if none.
+// It should be called once at the end of the reconcile, as the number of
+// errors is recorded in metrics on each call.
+func (in *ErrorGroup) Err() error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	controllerReconcileAggregatedErrors.WithLabelValues(in.controller).Observe(float64(len(in.errs)))
+	return kerrors.NewAggregate(in.errs)
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorGroup(t *testing.T) {
+	r := require.New(t)
+	controller := uniqueController("group")
+	ctx := WithController(context.Background(), controller)
+	notFound := errors.New("not found")
+
+	group := NewErrorGroup(ctx)
+	r.NoError(group.Err())
+
+	group = NewErrorGroup(ctx)
+	group.Add(nil, "object", "ignored")
+	group.Add(notFound, "kind", "ConfigMap", "object", "default/a")
+	group.Add(errors.New("conflict"), "kind", "Secret", "dangling")
+	group.Add(errors.New("timeout"))
+	r.Equal(3, group.Len())
+	err := group.Err()
+	r.EqualError(err, "[not found (kind=ConfigMap, object=default/a), conflict (kind=Secret, dangling), timeout]")
+	r.ErrorIs(err, notFound)
+
+	m := &dto.Metric{}
+	r.NoError(controllerReconcileAggregatedErrors.WithLabelValues(controller).(prometheus.Histogram).Write(m))
+	r.Equal(uint64(2), m.Histogram.GetSampleCount())
+	r.Equal(3.0, m.Histogram.GetSampleSum())
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// leader the leadership flag of the current process
+var leader atomic.Bool
+
+func init() {
+	leader.Store(true)
+}
+
+// SetLeader sets whether the current process is the leader. The process is
+// the leader by default, as without leader election every process does the
+// work, until WatchLeaderElection is called or the leadership is set.
+func SetLeader(isLeader bool) {
+	leader.Store(isLeader)
+}
+
+// WatchLeaderElection marks the current process as not the leader, and then
+// as the leader once elected is closed, such as the channel returned by the
+// Elected function of the controller manager. It should be called when leader
+// election is enabled, and returns immediately.
+func WatchLeaderElection(ctx context.Context, elected <-chan struct{}) {
+	SetLeader(false)
+	go func() {
+		select {
+		case <-elected:
+			SetLeader(true)
+		case <-ctx.Done():
+		}
+	}()
+}
+
+// WithLeader returns a copy of parent in which the leadership is overridden
+func WithLeader(parent context.Context, isLeader bool) context.Context {
+	return context.WithValue(parent, leaderContextKey, isLeader)
+}
+
+// IsLeader check if the current process is the leader. The leadership set by
+// WithLeader in the context takes precedence over the one set by SetLeader.
+func IsLeader(ctx context.Context) bool {
+	if isLeader, ok := ctx.Value(leaderContextKey).(bool); ok {
+		return isLeader
+	}
+	return leader.Load()
+}
+
+// RunIfLeader calls fn only if the current process is the leader, according
+// to IsLeader. It returns nil without calling fn otherwise.
+func RunIfLeader(ctx context.Context, fn func(context.Context) error) error {
+	if !IsLeader(ctx) {
+		return nil
+	}
+	return fn(ctx)
+}
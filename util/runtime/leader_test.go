@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubevela/pkg/util/runtime"
+)
+
+func TestRunIfLeader(t *testing.T) {
+	r := require.New(t)
+	defer runtime.SetLeader(true)
+	ctx := context.Background()
+	runs := 0
+	fn := func(context.Context) error {
+		runs++
+		return fmt.Errorf("run %d", runs)
+	}
+
+	// without leader election, the process is the leader
+	r.True(runtime.IsLeader(ctx))
+	r.EqualError(runtime.RunIfLeader(ctx, fn), "run 1")
+
+	runtime.SetLeader(false)
+	r.False(runtime.IsLeader(ctx))
+	r.NoError(runtime.RunIfLeader(ctx, fn))
+	r.Equal(1, runs)
+
+	runtime.SetLeader(true)
+	r.True(runtime.IsLeader(ctx))
+	r.EqualError(runtime.RunIfLeader(ctx, fn), "run 2")
+	// leadership in context takes precedence
+	r.NoError(runtime.RunIfLeader(runtime.WithLeader(ctx, false), fn))
+	r.Equal(2, runs)
+
+	runtime.SetLeader(false)
+	r.NoError(runtime.RunIfLeader(ctx, fn))
+	r.EqualError(runtime.RunIfLeader(runtime.WithLeader(ctx, true), fn), "run 3")
+
+	// with leader election, the process is not the leader until elected
+	runtime.SetLeader(true)
+	elected := make(chan struct{})
+	runtime.WatchLeaderElection(ctx, elected)
+	r.False(runtime.IsLeader(ctx))
+	close(elected)
+	r.Eventually(func() bool { return runtime.IsLeader(ctx) }, time.Second, 5*time.Millisecond)
+}
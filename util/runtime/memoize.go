@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/cache"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+)
+
+const (
+	// ControllerReconcileMemoizeKey metrics key for counting the memoized
+	// reconciles by cache hit or miss
+	ControllerReconcileMemoizeKey = "controller_reconcile_memoize_total"
+)
+
+var (
+	// controllerReconcileMemoize the memoized reconcile metrics, the hit
+	// ratio is the rate of hits divided by the rate of all
+	controllerReconcileMemoize = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerReconcileMemoizeKey,
+			Help: "number of memoized reconciles for kubevela controllers, by result of hit or miss. " +
+				`The hit ratio is sum by (controller) (rate(kubevela_controller_reconcile_memoize_total{result="hit"}[5m])) ` +
+				"/ sum by (controller) (rate(kubevela_controller_reconcile_memoize_total[5m]))",
+		}, []string{"controller", "result"})
+)
+
+var (
+	// MemoizeReconcileMaxEntries the max number of requests whose reconcile
+	// results are cached by one memoized reconciler
+	MemoizeReconcileMaxEntries = 10000
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerReconcileMemoize)
+}
+
+// memoizedResult the reconcile result cached for the input key
+type memoizedResult struct {
+	key    string
+	result reconcile.Result
+}
+
+// memoizeReconciler caches the last reconcile result for each request
+type memoizeReconciler struct {
+	reconcile.Reconciler
+	keyFn   func(reconcile.Request) string
+	ttl     time.Duration
+	results *cache.LRUExpireCache
+}
+
+// MemoizeReconcile wraps the reconciler which is a pure function of its
+// inputs, so that the last successful result of each request is returned
+// without invoking the reconciler again, if the input key returned by keyFn
+// is unchanged within the ttl. The input key must cover every input the
+// reconciler reads, such as the hash of the object together with the objects
+// it refers to, otherwise changes of the uncovered inputs are ignored until
+// the ttl expires. Any change of the input key invalidates the cached result.
+// Results with an error or Requeue are not cached, so that the failed
+// reconciles are retried. If keyFn returns an empty key, the reconciler is
+// always invoked. The controller label of the hit and miss metrics is
+// retrieved by ControllerFrom, or extracted from the callers if not set.
+func MemoizeReconcile(r reconcile.Reconciler, keyFn func(reconcile.Request) string, ttl time.Duration) reconcile.Reconciler {
+	return newMemoizeReconciler(r, keyFn, ttl, cache.NewLRUExpireCache(MemoizeReconcileMaxEntries))
+}
+
+func newMemoizeReconciler(r reconcile.Reconciler, keyFn func(reconcile.Request) string, ttl time.Duration, results *cache.LRUExpireCache) *memoizeReconciler {
+	return &memoizeReconciler{Reconciler: r, keyFn: keyFn, ttl: ttl, results: results}
+}
+
+// Reconcile .
+func (in *memoizeReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	controller, ok := ControllerFrom(ctx)
+	if !ok {
+		controller = GetControllerInCaller()
+	}
+	key := in.keyFn(req)
+	if key != "" {
+		if cached, found := in.results.Get(req.NamespacedName); found && cached.(*memoizedResult).key == key {
+			controllerReconcileMemoize.WithLabelValues(controller, "hit").Inc()
+			return cached.(*memoizedResult).result, nil
+		}
+	}
+	controllerReconcileMemoize.WithLabelValues(controller, "miss").Inc()
+	res, err := in.Reconciler.Reconcile(ctx, req)
+	if key == "" || err != nil || res.Requeue {
+		in.results.Remove(req.NamespacedName)
+	} else {
+		in.results.Add(req.NamespacedName, &memoizedResult{key: key, result: res}, in.ttl)
+	}
+	return res, err
+}
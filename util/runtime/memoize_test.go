@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/cache"
+	testingclock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestMemoizeReconcile(t *testing.T) {
+	r := require.New(t)
+	controller := uniqueController("memoize")
+	ctx := WithController(context.Background(), controller)
+	clock := testingclock.NewFakeClock(time.Now())
+	inputs := map[string]string{"a": "v1", "b": "v1", "c": "v1"}
+	invoked := 0
+	reconciler := newMemoizeReconciler(reconcile.Func(func(_ context.Context, req reconcile.Request) (reconcile.Result, error) {
+		invoked++
+		switch req.Name {
+		case "b":
+			return reconcile.Result{}, fmt.Errorf("invalid input %s", inputs[req.Name])
+		case "c":
+			return reconcile.Result{Requeue: true}, nil
+		}
+		return reconcile.Result{RequeueAfter: time.Duration(invoked) * time.Second}, nil
+	}), func(req reconcile.Request) string {
+		return inputs[req.Name]
+	}, time.Minute, cache.NewLRUExpireCacheWithClock(10, clock))
+	reqA := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "a"}}
+	reqB := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "b"}}
+	reqC := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "c"}}
+
+	// cached results are returned within ttl
+	res, err := reconciler.Reconcile(ctx, reqA)
+	r.NoError(err)
+	r.Equal(time.Second, res.RequeueAfter)
+	res, err = reconciler.Reconcile(ctx, reqA)
+	r.NoError(err)
+	r.Equal(time.Second, res.RequeueAfter)
+	r.Equal(1, invoked)
+	r.Equal(1.0, testutil.ToFloat64(controllerReconcileMemoize.WithLabelValues(controller, "hit")))
+	r.Equal(1.0, testutil.ToFloat64(controllerReconcileMemoize.WithLabelValues(controller, "miss")))
+
+	// errors and requeues are not cached
+	_, err = reconciler.Reconcile(ctx, reqB)
+	r.ErrorContains(err, "invalid input v1")
+	_, err = reconciler.Reconcile(ctx, reqB)
+	r.ErrorContains(err, "invalid input v1")
+	r.Equal(3, invoked)
+	res, err = reconciler.Reconcile(ctx, reqC)
+	r.NoError(err)
+	r.True(res.Requeue)
+	_, err = reconciler.Reconcile(ctx, reqC)
+	r.NoError(err)
+	r.Equal(5, invoked)
+	r.Equal(1.0, testutil.ToFloat64(controllerReconcileMemoize.WithLabelValues(controller, "hit")))
+	r.Equal(5.0, testutil.ToFloat64(controllerReconcileMemoize.WithLabelValues(controller, "miss")))
+
+	// changed inputs invalidate the cached result
+	inputs["a"] = "v2"
+	res, err = reconciler.Reconcile(ctx, reqA)
+	r.NoError(err)
+	r.Equal(6*time.Second, res.RequeueAfter)
+	r.Equal(6, invoked)
+
+	// expired results are not returned
+	clock.Step(2 * time.Minute)
+	res, err = reconciler.Reconcile(ctx, reqA)
+	r.NoError(err)
+	r.Equal(7*time.Second, res.RequeueAfter)
+
+	// empty input key always invokes the reconciler
+	inputs["a"] = ""
+	_, _ = reconciler.Reconcile(ctx, reqA)
+	_, _ = reconciler.Reconcile(ctx, reqA)
+	r.Equal(9, invoked)
+}
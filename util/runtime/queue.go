@@ -0,0 +1,220 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+)
+
+const (
+	// ControllerQueueWaitKey metrics key for recording the time items spend
+	// in the workqueue before being picked up
+	ControllerQueueWaitKey = "controller_queue_wait_seconds"
+	// ControllerBacklogOldestKey metrics key for recording the age of the
+	// oldest item waiting in the workqueue
+	ControllerBacklogOldestKey = "controller_backlog_oldest_seconds"
+)
+
+var (
+	// controllerQueueWait the queue wait metrics
+	controllerQueueWait = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerQueueWaitKey,
+			Help:      "time items spend in the workqueue before being picked up by kubevela controllers",
+			Buckets:   metrics.FineGrainedBuckets,
+		}, []string{"controller"})
+)
+
+// backlogCollector reports the age of the oldest waiting item of each queue
+// when collected
+type backlogCollector struct {
+	desc   *prometheus.Desc
+	mu     sync.Mutex
+	queues sync.Map
+}
+
+func newBacklogCollector() *backlogCollector {
+	return &backlogCollector{desc: prometheus.NewDesc(
+		prometheus.BuildFQName("", metrics.KubeVelaSubsystem, ControllerBacklogOldestKey),
+		"age of the oldest item waiting in the workqueue of kubevela controllers, 0 if no item is waiting",
+		[]string{"controller"}, nil)}
+}
+
+// Describe .
+func (in *backlogCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- in.desc
+}
+
+// Collect .
+func (in *backlogCollector) Collect(ch chan<- prometheus.Metric) {
+	in.queues.Range(func(key, value any) bool {
+		age := value.(*instrumentedQueue).oldestAge()
+		ch <- prometheus.MustNewConstMetric(in.desc, prometheus.GaugeValue, age.Seconds(), key.(string))
+		return true
+	})
+}
+
+// register the queue of the controller, replacing the previous one
+func (in *backlogCollector) register(controller string, q *instrumentedQueue) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.queues.Store(controller, q)
+}
+
+// unregister the queue of the controller if not replaced by another one
+func (in *backlogCollector) unregister(controller string, q *instrumentedQueue) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if value, found := in.queues.Load(controller); found && value == q {
+		in.queues.Delete(controller)
+	}
+}
+
+var controllerBacklog = newBacklogCollector()
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerQueueWait, controllerBacklog)
+}
+
+// instrumentedQueue records the time items wait in the queue. It sits below
+// the delaying queue, so the delay of AddAfter and AddRateLimited is not
+// counted as waiting: the wait starts when the item becomes ready. Items
+// re-added during processing start waiting when the processing is done, as
+// the queue holds them until then.
+type instrumentedQueue struct {
+	workqueue.Interface
+	controller string
+	clock      clock.PassiveClock
+
+	mu      sync.Mutex
+	addedAt map[interface{}]time.Time
+	// processing the items being processed, true if re-added
+	processing map[interface{}]bool
+}
+
+// NewInstrumentedQueue creates a rate limiting workqueue for the controller,
+// recording the time from an item being ready to being picked up, and the age
+// of the oldest waiting item.
+func NewInstrumentedQueue(controller string, rateLimiter workqueue.RateLimiter) workqueue.RateLimitingInterface {
+	return newInstrumentedQueue(controller, rateLimiter, clock.RealClock{})
+}
+
+func newInstrumentedQueue(controller string, rateLimiter workqueue.RateLimiter, c clock.PassiveClock) workqueue.RateLimitingInterface {
+	q := &instrumentedQueue{
+		Interface:  workqueue.NewNamed(controller),
+		controller: controller,
+		clock:      c,
+		addedAt:    map[interface{}]time.Time{},
+		processing: map[interface{}]bool{},
+	}
+	controllerBacklog.register(controller, q)
+	return workqueue.NewRateLimitingQueueWithDelayingInterface(
+		workqueue.NewDelayingQueueWithCustomQueue(q, controller), rateLimiter)
+}
+
+// Add records the time the item is added. Items already waiting in the queue
+// keep the earliest time, as the queue merges them. Items added after
+// shutdown are ignored.
+func (in *instrumentedQueue) Add(item interface{}) {
+	in.mu.Lock()
+	_, processing := in.processing[item]
+	_, found := in.addedAt[item]
+	switch {
+	case in.Interface.ShuttingDown():
+		// the item is dropped by the queue
+	case processing:
+		in.processing[item] = true
+	case !found:
+		in.addedAt[item] = in.clock.Now()
+	}
+	in.mu.Unlock()
+	in.Interface.Add(item)
+}
+
+// Get records the time the item waited in the queue
+func (in *instrumentedQueue) Get() (interface{}, bool) {
+	item, shutdown := in.Interface.Get()
+	if shutdown {
+		return item, shutdown
+	}
+	in.mu.Lock()
+	addedAt, found := in.addedAt[item]
+	delete(in.addedAt, item)
+	in.processing[item] = false
+	in.mu.Unlock()
+	if found {
+		controllerQueueWait.WithLabelValues(in.controller).Observe(in.clock.Since(addedAt).Seconds())
+	}
+	return item, shutdown
+}
+
+// Done starts the wait of the item if it is re-added during processing
+func (in *instrumentedQueue) Done(item interface{}) {
+	in.mu.Lock()
+	if readded := in.processing[item]; readded {
+		in.addedAt[item] = in.clock.Now()
+	}
+	delete(in.processing, item)
+	in.mu.Unlock()
+	in.Interface.Done(item)
+}
+
+// ShutDown stops reporting the queue and clears the waiting items
+func (in *instrumentedQueue) ShutDown() {
+	in.Interface.ShutDown()
+	in.cleanup()
+}
+
+// ShutDownWithDrain stops reporting the queue and clears the waiting items
+// after the processing items are done
+func (in *instrumentedQueue) ShutDownWithDrain() {
+	in.Interface.ShutDownWithDrain()
+	in.cleanup()
+}
+
+func (in *instrumentedQueue) cleanup() {
+	controllerBacklog.unregister(in.controller, in)
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.addedAt = map[interface{}]time.Time{}
+	in.processing = map[interface{}]bool{}
+}
+
+// oldestAge returns the waiting time of the oldest item in the queue
+func (in *instrumentedQueue) oldestAge() time.Duration {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	var oldest time.Time
+	for _, addedAt := range in.addedAt {
+		if oldest.IsZero() || addedAt.Before(oldest) {
+			oldest = addedAt
+		}
+	}
+	if oldest.IsZero() {
+		return 0
+	}
+	return in.clock.Since(oldest)
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/util/workqueue"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func TestInstrumentedQueueWait(t *testing.T) {
+	r := require.New(t)
+	c := testingclock.NewFakePassiveClock(time.Now())
+	controller := uniqueController("queue")
+	q := newInstrumentedQueue(controller, workqueue.DefaultControllerRateLimiter(), c)
+	defer q.ShutDown()
+
+	q.Add("a")
+	c.SetTime(c.Now().Add(2 * time.Second))
+	q.Add("a")
+	c.SetTime(c.Now().Add(time.Second))
+	item, shutdown := q.Get()
+	r.False(shutdown)
+	r.Equal("a", item)
+	q.Done(item)
+
+	m := &dto.Metric{}
+	r.NoError(controllerQueueWait.WithLabelValues(controller).(prometheus.Histogram).Write(m))
+	r.Equal(uint64(1), m.Histogram.GetSampleCount())
+	r.InDelta(3.0, m.Histogram.GetSampleSum(), 1e-6)
+}
+
+func TestInstrumentedQueueBacklog(t *testing.T) {
+	r := require.New(t)
+	c := testingclock.NewFakePassiveClock(time.Now())
+	q := newInstrumentedQueue("backlog", workqueue.DefaultControllerRateLimiter(), c)
+	defer q.ShutDown()
+	value, _ := controllerBacklog.queues.Load("backlog")
+	collector := newBacklogCollector()
+	collector.queues.Store("backlog", value)
+	r.Equal(0.0, testutil.ToFloat64(collector))
+
+	q.Add("a")
+	c.SetTime(c.Now().Add(5 * time.Second))
+	q.Add("b")
+	c.SetTime(c.Now().Add(3 * time.Second))
+	r.Equal(8.0, testutil.ToFloat64(collector))
+
+	item, _ := q.Get()
+	r.Equal("a", item)
+	r.Equal(3.0, testutil.ToFloat64(collector))
+	q.Done(item)
+	item, _ = q.Get()
+	q.Done(item)
+	r.Equal(0.0, testutil.ToFloat64(collector))
+}
+
+func TestInstrumentedQueueReaddAndShutDown(t *testing.T) {
+	r := require.New(t)
+	c := testingclock.NewFakePassiveClock(time.Now())
+	q := newInstrumentedQueue("readd", workqueue.DefaultControllerRateLimiter(), c)
+	value, _ := controllerBacklog.queues.Load("readd")
+	iq := value.(*instrumentedQueue)
+
+	// items re-added during processing wait from the processing done
+	q.Add("a")
+	item, _ := q.Get()
+	q.Add("a")
+	c.SetTime(c.Now().Add(5 * time.Second))
+	r.Equal(time.Duration(0), iq.oldestAge())
+	q.Done(item)
+	c.SetTime(c.Now().Add(time.Second))
+	r.Equal(time.Second, iq.oldestAge())
+
+	// recreated queues replace the old ones, which are not reported after shutdown
+	recreated := newInstrumentedQueue("readd", workqueue.DefaultControllerRateLimiter(), c)
+	q.ShutDown()
+	r.Equal(0, len(iq.addedAt))
+	value, found := controllerBacklog.queues.Load("readd")
+	r.True(found)
+	r.NotSame(iq, value)
+	recreated.ShutDown()
+	_, found = controllerBacklog.queues.Load("readd")
+	r.False(found)
+}
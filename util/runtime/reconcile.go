@@ -0,0 +1,218 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+)
+
+const (
+	// ControllerReconcileLatencyKey metrics key for recording time cost of
+	// controller reconciles
+	ControllerReconcileLatencyKey = "controller_reconcile_time_seconds"
+	// ControllerReconcileInFlightKey metrics key for recording the number of
+	// in-flight controller reconciles
+	ControllerReconcileInFlightKey = "controller_reconcile_in_flight"
+
+	// ReconcileResultSuccess the result label for succeeded reconciles
+	ReconcileResultSuccess = "success"
+	// ReconcileResultError the result label for failed reconciles
+	ReconcileResultError = "error"
+
+	// ReconcilePhaseCold the phase label for reconciles right after the
+	// controller starts, which are usually slowed down by cache warming
+	ReconcilePhaseCold = "cold"
+	// ReconcilePhaseWarm the phase label for steady-state reconciles
+	ReconcilePhaseWarm = "warm"
+)
+
+var (
+	// ColdStartReconciles the number of reconciles since the controller starts
+	// to be recorded under the cold phase by MonitorReconcile, so that cache
+	// warming at startup does not skew the steady-state latency
+	ColdStartReconciles int64 = 10
+)
+
+var (
+	// controllerReconcileLatency the reconcile latency metrics
+	controllerReconcileLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerReconcileLatencyKey,
+			Help:      "reconcile duration for kubevela controllers",
+			Buckets:   metrics.FineGrainedBuckets,
+		}, []string{"controller", "result", "phase", "trigger"})
+
+	// controllerReconcileInFlight the in-flight reconciles metrics
+	controllerReconcileInFlight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerReconcileInFlightKey,
+			Help:      "number of in-flight reconciles for kubevela controllers",
+		}, []string{"controller"})
+)
+
+var (
+	// otelReconcileLatency mirrors the reconcile latency metrics in OTel,
+	// which is recorded if an OTel meter provider is registered
+	otelReconcileLatency = metrics.NewOTelHistogram(ControllerReconcileLatencyKey, "reconcile duration for kubevela controllers")
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerReconcileLatency, controllerReconcileInFlight)
+}
+
+// reconcileStats the counters of reconciles for one controller. All fields
+// are updated atomically so that reading stats does not block reconciles.
+type reconcileStats struct {
+	total     int64
+	errors    int64
+	inFlight  int64
+	durations int64
+}
+
+var controllerReconcileStats sync.Map
+
+func getReconcileStats(controller string) *reconcileStats {
+	if stats, found := controllerReconcileStats.Load(controller); found {
+		return stats.(*reconcileStats)
+	}
+	stats, _ := controllerReconcileStats.LoadOrStore(controller, &reconcileStats{})
+	return stats.(*reconcileStats)
+}
+
+func (in *reconcileStats) snapshot() ReconcileStatsSnapshot {
+	// errors are loaded before total and increased after total, to make sure
+	// errors never exceed total in the snapshot
+	s := ReconcileStatsSnapshot{Errors: atomic.LoadInt64(&in.errors)}
+	s.Total = atomic.LoadInt64(&in.total)
+	s.InFlight = atomic.LoadInt64(&in.inFlight)
+	s.Successes = s.Total - s.Errors
+	if s.Total > 0 {
+		s.AverageDuration = metav1.Duration{Duration: time.Duration(atomic.LoadInt64(&in.durations) / s.Total)}
+	}
+	return s
+}
+
+// ReconcileStatsSnapshot the snapshot of reconcile stats, which can be
+// embedded in the status of controller or exposed by debug endpoints
+type ReconcileStatsSnapshot struct {
+	Total           int64           `json:"total"`
+	Successes       int64           `json:"successes"`
+	Errors          int64           `json:"errors"`
+	AverageDuration metav1.Duration `json:"averageDuration"`
+	InFlight        int64           `json:"inFlight"`
+}
+
+// ReconcileStats returns the snapshot of reconcile stats for all controllers
+// monitored by MonitorReconcile
+func ReconcileStats() ReconcileStatsSnapshot {
+	s := ReconcileStatsSnapshot{}
+	var durations time.Duration
+	controllerReconcileStats.Range(func(_, value any) bool {
+		_s := value.(*reconcileStats).snapshot()
+		s.Total += _s.Total
+		s.Successes += _s.Successes
+		s.Errors += _s.Errors
+		s.InFlight += _s.InFlight
+		durations += _s.AverageDuration.Duration * time.Duration(_s.Total)
+		return true
+	})
+	if s.Total > 0 {
+		s.AverageDuration = metav1.Duration{Duration: durations / time.Duration(s.Total)}
+	}
+	return s
+}
+
+// ControllerReconcileStats returns the snapshot of reconcile stats for the
+// given controller
+func ControllerReconcileStats(controller string) ReconcileStatsSnapshot {
+	return getReconcileStats(controller).snapshot()
+}
+
+// monitorReconciler records the reconcile metrics and stats
+type monitorReconciler struct {
+	reconcile.Reconciler
+	controller string
+	storms     *requeueStormDetector
+	distinct   *distinctObjectsCounter
+	reconciles int64
+}
+
+// MonitorReconcile wraps the reconciler to record the reconcile metrics and
+// stats under the given controller name. The controller name is also set in
+// the reconcile context, which can be retrieved by ControllerFrom.
+func MonitorReconcile(controller string, r reconcile.Reconciler) reconcile.Reconciler {
+	return &monitorReconciler{Reconciler: r, controller: controller, storms: newRequeueStormDetector(), distinct: newDistinctObjectsCounter(controller)}
+}
+
+// Reconcile .
+func (in *monitorReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	stats := getReconcileStats(in.controller)
+	atomic.AddInt64(&stats.inFlight, 1)
+	controllerReconcileInFlight.WithLabelValues(in.controller).Inc()
+	n := atomic.AddInt64(&in.reconciles, 1)
+	phase := ReconcilePhaseWarm
+	if n <= ColdStartReconciles {
+		phase = ReconcilePhaseCold
+	}
+	trigger := getTriggerTracker(in.controller).take(req.NamespacedName)
+	begin := time.Now()
+	in.distinct.observe(req.NamespacedName, begin)
+	if cnt, storm := in.storms.observe(req.NamespacedName, begin); storm {
+		controllerRequeueStorm.WithLabelValues(in.controller).Inc()
+		klog.Warningf("requeue storm detected: %s reconciled %s %d times within %s", in.controller, req.NamespacedName, cnt, RequeueStormWindow)
+	}
+	defer func() {
+		atomic.AddInt64(&stats.inFlight, -1)
+		controllerReconcileInFlight.WithLabelValues(in.controller).Dec()
+	}()
+
+	stopAlloc := monitorAlloc(in.controller, n)
+	res, err := in.Reconciler.Reconcile(WithController(ctx, in.controller), req)
+	stopAlloc()
+
+	d := time.Since(begin)
+	atomic.AddInt64(&stats.durations, int64(d))
+	atomic.AddInt64(&stats.total, 1)
+	result := ReconcileResultSuccess
+	if err != nil {
+		result = ReconcileResultError
+		atomic.AddInt64(&stats.errors, 1)
+		controllerReconcileError.WithLabelValues(in.controller, errorCategoryLabel(ClassifyError(err))).Inc()
+	}
+	controllerReconcileLatency.WithLabelValues(in.controller, result, phase, trigger).Observe(d.Seconds())
+	otelReconcileLatency.Record(ctx, d.Seconds(),
+		attribute.String("controller", in.controller),
+		attribute.String("result", result),
+		attribute.String("phase", phase),
+		attribute.String("trigger", trigger),
+	)
+	return res, err
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kubevela/pkg/util/runtime"
+)
+
+var controllerSeq int64
+
+// uniqueController returns a controller name not used by the previous runs,
+// as the reconcile stats and metrics are kept globally per controller
+func uniqueController(prefix string) string {
+	return fmt.Sprintf("%s-%d", prefix, atomic.AddInt64(&controllerSeq, 1))
+}
+
+func TestMonitorReconcile(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	name := uniqueController("stats")
+	before := runtime.ReconcileStats()
+	release := make(chan struct{})
+	reconciler := runtime.MonitorReconcile(name, reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		controller, _ := runtime.ControllerFrom(ctx)
+		r.Equal(name, controller)
+		switch req.Name {
+		case "error":
+			return reconcile.Result{}, fmt.Errorf("failed")
+		case "block":
+			<-release
+		}
+		time.Sleep(10 * time.Millisecond)
+		return reconcile.Result{}, nil
+	}))
+	for _, name := range []string{"a", "b", "error"} {
+		_, _ = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+	}
+	done := make(chan struct{})
+	go func() {
+		_, _ = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "block"}})
+		close(done)
+	}()
+	r.Eventually(func() bool { return runtime.ControllerReconcileStats(name).InFlight == 1 }, time.Second, 5*time.Millisecond)
+
+	stats := runtime.ControllerReconcileStats(name)
+	r.Equal(int64(3), stats.Total)
+	r.Equal(int64(2), stats.Successes)
+	r.Equal(int64(1), stats.Errors)
+	r.Greater(stats.AverageDuration.Duration, time.Duration(0))
+
+	after := runtime.ReconcileStats()
+	r.Equal(before.Total+3, after.Total)
+	r.Equal(before.Errors+1, after.Errors)
+	r.Equal(before.InFlight+1, after.InFlight)
+	close(release)
+	<-done
+	r.Equal(int64(0), runtime.ControllerReconcileStats(name).InFlight)
+	r.Equal(int64(4), runtime.ControllerReconcileStats(name).Total)
+}
+
+func TestMonitorReconcilePhase(t *testing.T) {
+	r := require.New(t)
+	defer func(cnt int64) { runtime.ColdStartReconciles = cnt }(runtime.ColdStartReconciles)
+	runtime.ColdStartReconciles = 1
+	name := uniqueController("phase")
+	reconciler := runtime.MonitorReconcile(name, reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		return reconcile.Result{}, nil
+	}))
+	countByPhase := func() map[string]uint64 {
+		counts := map[string]uint64{}
+		families, err := ctrlmetrics.Registry.Gather()
+		r.NoError(err)
+		for _, family := range families {
+			if family.GetName() != "kubevela_"+runtime.ControllerReconcileLatencyKey {
+				continue
+			}
+			for _, m := range family.Metric {
+				labels := map[string]string{}
+				for _, label := range m.Label {
+					labels[label.GetName()] = label.GetValue()
+				}
+				if labels["controller"] == name {
+					counts[labels["phase"]] += m.Histogram.GetSampleCount()
+				}
+			}
+		}
+		return counts
+	}
+	_, _ = reconciler.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "a"}})
+	r.Equal(map[string]uint64{runtime.ReconcilePhaseCold: 1}, countByPhase())
+	_, _ = reconciler.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "a"}})
+	_, _ = reconciler.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "b"}})
+	r.Equal(map[string]uint64{runtime.ReconcilePhaseCold: 1, runtime.ReconcilePhaseWarm: 2}, countByPhase())
+}
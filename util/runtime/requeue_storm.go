@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+)
+
+const (
+	// ControllerRequeueStormKey metrics key for counting the detected
+	// requeue storms of controller reconciles
+	ControllerRequeueStormKey = "controller_requeue_storm_total"
+)
+
+var (
+	// controllerRequeueStorm the requeue storm metrics
+	controllerRequeueStorm = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerRequeueStormKey,
+			Help:      "number of objects reconciled too frequently by kubevela controllers",
+		}, []string{"controller"})
+)
+
+var (
+	// RequeueStormWindow the time window for counting reconciles of one object
+	RequeueStormWindow = time.Minute
+	// RequeueStormThreshold the max number of reconciles for one object within
+	// the window. Exceeding it is logged and counted as requeue storm by
+	// MonitorReconcile. If not positive, requeue storms will not be detected.
+	RequeueStormThreshold = 300
+	// RequeueStormMaxTrackedObjects the max number of objects tracked by one
+	// controller for detecting requeue storms
+	RequeueStormMaxTrackedObjects = 10000
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerRequeueStorm)
+}
+
+// requeueCounter counts the reconciles of one object in the current window
+type requeueCounter struct {
+	begin   time.Time
+	count   int
+	flagged bool
+}
+
+// requeueStormDetector counts reconciles of each object in fixed windows
+type requeueStormDetector struct {
+	mu       sync.Mutex
+	counters map[types.NamespacedName]*requeueCounter
+}
+
+func newRequeueStormDetector() *requeueStormDetector {
+	return &requeueStormDetector{counters: map[types.NamespacedName]*requeueCounter{}}
+}
+
+// observe records one reconcile of the object and returns the number of
+// reconciles in the current window if the object is detected as requeue storm
+// for the first time in the window
+func (in *requeueStormDetector) observe(key types.NamespacedName, now time.Time) (int, bool) {
+	if RequeueStormThreshold <= 0 {
+		return 0, false
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	counter, found := in.counters[key]
+	if !found {
+		if len(in.counters) >= RequeueStormMaxTrackedObjects {
+			in.evict(now)
+		}
+		counter = &requeueCounter{begin: now}
+		in.counters[key] = counter
+	}
+	if now.Sub(counter.begin) > RequeueStormWindow {
+		*counter = requeueCounter{begin: now}
+	}
+	counter.count++
+	if counter.count > RequeueStormThreshold && !counter.flagged {
+		counter.flagged = true
+		return counter.count, true
+	}
+	return 0, false
+}
+
+// evict removes the counters of expired windows. If the tracked objects still
+// reach the limit, all counters are dropped to bound the memory.
+func (in *requeueStormDetector) evict(now time.Time) {
+	for key, counter := range in.counters {
+		if now.Sub(counter.begin) > RequeueStormWindow {
+			delete(in.counters, key)
+		}
+	}
+	if len(in.counters) >= RequeueStormMaxTrackedObjects {
+		in.counters = map[types.NamespacedName]*requeueCounter{}
+	}
+}
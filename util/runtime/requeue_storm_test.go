@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var controllerSeq int64
+
+// uniqueController returns a controller name not used by the previous runs,
+// as the reconcile metrics and states are kept globally per controller
+func uniqueController(prefix string) string {
+	return fmt.Sprintf("%s-%d", prefix, atomic.AddInt64(&controllerSeq, 1))
+}
+
+func TestRequeueStorm(t *testing.T) {
+	defer func(threshold int) { RequeueStormThreshold = threshold }(RequeueStormThreshold)
+	RequeueStormThreshold = 10
+	controller := uniqueController("storm")
+	r := MonitorReconcile(controller, reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+		return reconcile.Result{Requeue: true}, nil
+	}))
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "hot"}}
+	for i := 0; i < 50; i++ {
+		_, _ = r.Reconcile(context.Background(), req)
+	}
+	_, _ = r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "cold"}})
+	require.Equal(t, 1.0, testutil.ToFloat64(controllerRequeueStorm.WithLabelValues(controller)))
+}
+
+func TestRequeueStormDetector(t *testing.T) {
+	defer func(threshold, max int) {
+		RequeueStormThreshold, RequeueStormMaxTrackedObjects = threshold, max
+	}(RequeueStormThreshold, RequeueStormMaxTrackedObjects)
+	RequeueStormThreshold, RequeueStormMaxTrackedObjects = 2, 5
+	detector := newRequeueStormDetector()
+	key := types.NamespacedName{Name: "hot"}
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		_, storm := detector.observe(key, now)
+		require.False(t, storm)
+	}
+	cnt, storm := detector.observe(key, now)
+	require.True(t, storm)
+	require.Equal(t, 3, cnt)
+	_, storm = detector.observe(key, now)
+	require.False(t, storm)
+
+	now = now.Add(RequeueStormWindow + time.Second)
+	_, storm = detector.observe(key, now)
+	require.False(t, storm)
+	require.Equal(t, 1, detector.counters[key].count)
+
+	for i := 0; i < 20; i++ {
+		detector.observe(types.NamespacedName{Name: fmt.Sprintf("obj-%d", i)}, now)
+		require.LessOrEqual(t, len(detector.counters), RequeueStormMaxTrackedObjects)
+	}
+
+	RequeueStormThreshold = 0
+	_, storm = detector.observe(key, now)
+	require.False(t, storm)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+)
+
+const (
+	// ControllerWorkerSaturationKey metrics key for recording the ratio of
+	// in-flight reconciles to the max concurrent reconciles
+	ControllerWorkerSaturationKey = "controller_worker_saturation_ratio"
+)
+
+// saturationCollector reports the worker saturation of each controller with
+// configured concurrency when collected
+type saturationCollector struct {
+	desc          *prometheus.Desc
+	maxConcurrent sync.Map
+}
+
+func newSaturationCollector() *saturationCollector {
+	return &saturationCollector{desc: prometheus.NewDesc(
+		prometheus.BuildFQName("", metrics.KubeVelaSubsystem, ControllerWorkerSaturationKey),
+		"ratio of in-flight reconciles to the max concurrent reconciles of kubevela controllers",
+		[]string{"controller"}, nil)}
+}
+
+// Describe .
+func (in *saturationCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- in.desc
+}
+
+// Collect .
+func (in *saturationCollector) Collect(ch chan<- prometheus.Metric) {
+	in.maxConcurrent.Range(func(key, value any) bool {
+		ch <- prometheus.MustNewConstMetric(in.desc, prometheus.GaugeValue, workerSaturation(key.(string), value.(int)), key.(string))
+		return true
+	})
+}
+
+func workerSaturation(controller string, maxConcurrent int) float64 {
+	inFlight := atomic.LoadInt64(&getReconcileStats(controller).inFlight)
+	return float64(inFlight) / float64(maxConcurrent)
+}
+
+var controllerWorkerSaturation = newSaturationCollector()
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerWorkerSaturation)
+}
+
+// SetMaxConcurrentReconciles sets the max concurrent reconciles configured
+// for the controller, usually the MaxConcurrentReconciles in the controller
+// options. The worker saturation of the controller, i.e. the in-flight
+// reconciles monitored by MonitorReconcile divided by the max concurrent
+// reconciles, is then exposed, which can be used for autoscaling the
+// controller replicas. Non-positive maxConcurrent stops exposing it.
+func SetMaxConcurrentReconciles(controller string, maxConcurrent int) {
+	if maxConcurrent <= 0 {
+		controllerWorkerSaturation.maxConcurrent.Delete(controller)
+		return
+	}
+	controllerWorkerSaturation.maxConcurrent.Store(controller, maxConcurrent)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestWorkerSaturation(t *testing.T) {
+	r := require.New(t)
+	SetMaxConcurrentReconciles("saturation", 2)
+	defer SetMaxConcurrentReconciles("saturation", 0)
+	value, _ := controllerWorkerSaturation.maxConcurrent.Load("saturation")
+	collector := newSaturationCollector()
+	collector.maxConcurrent.Store("saturation", value)
+	r.Equal(0.0, testutil.ToFloat64(collector))
+
+	release := make(chan struct{})
+	reconciler := MonitorReconcile("saturation", reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		<-release
+		return reconcile.Result{}, nil
+	}))
+	wg := sync.WaitGroup{}
+	start := func() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = reconciler.Reconcile(context.Background(), reconcile.Request{})
+		}()
+	}
+	start()
+	r.Eventually(func() bool { return testutil.ToFloat64(collector) == 0.5 }, time.Second, 5*time.Millisecond)
+	start()
+	r.Eventually(func() bool { return testutil.ToFloat64(collector) == 1.0 }, time.Second, 5*time.Millisecond)
+	close(release)
+	wg.Wait()
+	r.Equal(0.0, testutil.ToFloat64(collector))
+
+	SetMaxConcurrentReconciles("saturation", 0)
+	_, found := controllerWorkerSaturation.maxConcurrent.Load("saturation")
+	r.False(found)
+}
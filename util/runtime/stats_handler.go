@@ -0,0 +1,146 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// statsPercentiles the latency percentiles reported by StatsHandler
+var statsPercentiles = map[string]float64{"p50": 0.5, "p90": 0.9, "p99": 0.99}
+
+// ControllerStats the reconcile stats of one controller reported by
+// StatsHandler
+type ControllerStats struct {
+	ReconcileStatsSnapshot
+	// ErrorRate the ratio of failed reconciles
+	ErrorRate float64 `json:"errorRate"`
+	// LatencyPercentiles the reconcile latency percentiles in seconds,
+	// estimated from the histogram buckets
+	LatencyPercentiles map[string]float64 `json:"latencyPercentiles"`
+}
+
+// StatsResponse the response of StatsHandler
+type StatsResponse struct {
+	Controllers map[string]ControllerStats `json:"controllers"`
+}
+
+// StatsHandler returns the http handler which reports the reconcile stats of
+// controllers monitored by MonitorReconcile in JSON. It is a lightweight
+// alternative to scraping the metrics for small deployments.
+func StatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(getStatsResponse()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// getStatsResponse collects the reconcile stats reported by StatsHandler
+func getStatsResponse() StatsResponse {
+	resp := StatsResponse{Controllers: map[string]ControllerStats{}}
+	histograms := collectLatencyHistograms()
+	controllerReconcileStats.Range(func(key, value any) bool {
+		controller := key.(string)
+		stats := ControllerStats{ReconcileStatsSnapshot: value.(*reconcileStats).snapshot(), LatencyPercentiles: map[string]float64{}}
+		if stats.Total > 0 {
+			stats.ErrorRate = float64(stats.Errors) / float64(stats.Total)
+		}
+		if h, found := histograms[controller]; found {
+			for name, q := range statsPercentiles {
+				stats.LatencyPercentiles[name] = h.quantile(q)
+			}
+		}
+		resp.Controllers[controller] = stats
+		return true
+	})
+	return resp
+}
+
+// latencyHistogram the cumulative bucket counts merged across labels
+type latencyHistogram struct {
+	count   uint64
+	buckets map[float64]uint64
+}
+
+// collectLatencyHistograms merges the reconcile latency histograms of each
+// controller across the other labels
+func collectLatencyHistograms() map[string]*latencyHistogram {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		controllerReconcileLatency.Collect(ch)
+		close(ch)
+	}()
+	histograms := map[string]*latencyHistogram{}
+	for m := range ch {
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil || pb.Histogram == nil {
+			continue
+		}
+		controller := ""
+		for _, label := range pb.Label {
+			if label.GetName() == "controller" {
+				controller = label.GetValue()
+			}
+		}
+		h, found := histograms[controller]
+		if !found {
+			h = &latencyHistogram{buckets: map[float64]uint64{}}
+			histograms[controller] = h
+		}
+		h.count += pb.Histogram.GetSampleCount()
+		for _, b := range pb.Histogram.Bucket {
+			h.buckets[b.GetUpperBound()] += b.GetCumulativeCount()
+		}
+	}
+	return histograms
+}
+
+// quantile estimates the quantile by linear interpolation within the bucket,
+// in the same way as the histogram_quantile function of Prometheus. If the
+// quantile falls beyond the largest bucket, the upper bound of the largest
+// bucket is returned.
+func (in *latencyHistogram) quantile(q float64) float64 {
+	if in.count == 0 {
+		return 0
+	}
+	bounds := make([]float64, 0, len(in.buckets))
+	for bound := range in.buckets {
+		bounds = append(bounds, bound)
+	}
+	sort.Float64s(bounds)
+	rank := q * float64(in.count)
+	lower, lowerCount := 0.0, 0.0
+	for _, bound := range bounds {
+		count := float64(in.buckets[bound])
+		if count >= rank {
+			if math.IsInf(bound, 1) {
+				return lower
+			}
+			return lower + (bound-lower)*(rank-lowerCount)/(count-lowerCount)
+		}
+		lower, lowerCount = bound, count
+	}
+	return lower
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kubevela/pkg/util/runtime"
+)
+
+func TestStatsHandler(t *testing.T) {
+	r := require.New(t)
+	name := uniqueController("handler")
+	reconciler := runtime.MonitorReconcile(name, reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		if req.Name == "error" {
+			return reconcile.Result{}, fmt.Errorf("failed")
+		}
+		time.Sleep(5 * time.Millisecond)
+		return reconcile.Result{}, nil
+	}))
+	for _, name := range []string{"a", "b", "c", "error"} {
+		_, _ = reconciler.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+	}
+
+	server := httptest.NewServer(runtime.StatsHandler())
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	r.NoError(err)
+	defer resp.Body.Close()
+	r.Equal(http.StatusOK, resp.StatusCode)
+	r.Equal("application/json", resp.Header.Get("Content-Type"))
+
+	body := map[string]map[string]map[string]interface{}{}
+	r.NoError(json.NewDecoder(resp.Body).Decode(&body))
+	stats, found := body["controllers"][name]
+	r.True(found)
+	r.Equal(4.0, stats["total"])
+	r.Equal(3.0, stats["successes"])
+	r.Equal(1.0, stats["errors"])
+	r.Equal(0.25, stats["errorRate"])
+	r.Contains(stats, "averageDuration")
+	r.Equal(0.0, stats["inFlight"])
+	percentiles, ok := stats["latencyPercentiles"].(map[string]interface{})
+	r.True(ok)
+	for _, name := range []string{"p50", "p90", "p99"} {
+		r.Greater(percentiles[name], 0.0)
+	}
+	r.LessOrEqual(percentiles["p50"], percentiles["p99"])
+}
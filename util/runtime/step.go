@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kubevela/pkg/monitor/metrics"
+)
+
+const (
+	// ControllerReconcileStepLatencyKey metrics key for recording time cost
+	// of the steps in controller reconciles
+	ControllerReconcileStepLatencyKey = "controller_reconcile_step_seconds"
+)
+
+var (
+	// controllerReconcileStepLatency the reconcile step latency metrics
+	controllerReconcileStepLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: metrics.KubeVelaSubsystem,
+			Name:      ControllerReconcileStepLatencyKey,
+			Help:      "reconcile step duration for kubevela controllers",
+			Buckets:   metrics.FineGrainedBuckets,
+		}, []string{"controller", "step"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerReconcileStepLatency)
+}
+
+// Step starts timing the step of the reconcile and returns the closer, which
+// records the duration under the controller and the step name. The controller
+// is retrieved by ControllerFrom, or extracted from the callers if not set. If
+// the context carries a reconcile trace, the step is recorded as well.
+func Step(ctx context.Context, name string) func() {
+	controller, ok := ControllerFrom(ctx)
+	if !ok {
+		controller = GetControllerInCaller()
+	}
+	begin := time.Now()
+	return func() {
+		d := time.Since(begin)
+		controllerReconcileStepLatency.WithLabelValues(controller, name).Observe(d.Seconds())
+		if trace := TraceFrom(ctx); trace != nil {
+			trace.Record(name, begin, d)
+		}
+	}
+}
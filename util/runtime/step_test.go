@@ -0,0 +1,48 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStep(t *testing.T) {
+	r := require.New(t)
+	controller := uniqueController("step")
+	ctx, trace := NewReconcileTrace(WithController(context.Background(), controller))
+	render := Step(ctx, "render")
+	time.Sleep(10 * time.Millisecond)
+	render()
+	apply := Step(ctx, "apply")
+	apply()
+
+	m := &dto.Metric{}
+	r.NoError(controllerReconcileStepLatency.WithLabelValues(controller, "render").(prometheus.Histogram).Write(m))
+	r.Equal(uint64(1), m.Histogram.GetSampleCount())
+	r.GreaterOrEqual(m.Histogram.GetSampleSum(), 0.01)
+	r.NoError(controllerReconcileStepLatency.WithLabelValues(controller, "apply").(prometheus.Histogram).Write(m))
+	r.Equal(uint64(1), m.Histogram.GetSampleCount())
+	r.Len(trace.root.Steps, 2)
+	r.Equal("render", trace.root.Steps[0].Name)
+	r.Equal("apply", trace.root.Steps[1].Name)
+}
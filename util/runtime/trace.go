@@ -0,0 +1,116 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+var (
+	// ReconcileTraceThreshold the threshold for dumping reconcile trace. Only
+	// reconciles taking longer than the threshold will be dumped.
+	ReconcileTraceThreshold = time.Second
+)
+
+// TraceStep a timed step in the reconcile trace
+type TraceStep struct {
+	Name     string
+	Begin    time.Time
+	Duration time.Duration
+	Steps    []*TraceStep
+}
+
+// Trace records the tree of timed steps during the reconcile
+// Steps are nested in the order of calling: a step started before the
+// previous one closed becomes its child.
+type Trace struct {
+	mu    sync.Mutex
+	root  *TraceStep
+	stack []*TraceStep
+}
+
+// NewReconcileTrace create a reconcile trace and attach it to the context
+func NewReconcileTrace(ctx context.Context) (context.Context, *Trace) {
+	root := &TraceStep{Name: "reconcile", Begin: time.Now()}
+	trace := &Trace{root: root, stack: []*TraceStep{root}}
+	return context.WithValue(ctx, traceContextKey, trace), trace
+}
+
+// TraceFrom extract the reconcile trace from context, nil if not exists
+func TraceFrom(ctx context.Context) *Trace {
+	trace, _ := ctx.Value(traceContextKey).(*Trace)
+	return trace
+}
+
+// Step start a step under the current step and return the closer
+func (in *Trace) Step(name string) func() {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	step := &TraceStep{Name: name, Begin: time.Now()}
+	parent := in.stack[len(in.stack)-1]
+	parent.Steps = append(parent.Steps, step)
+	in.stack = append(in.stack, step)
+	return func() {
+		in.mu.Lock()
+		defer in.mu.Unlock()
+		step.Duration = time.Since(step.Begin)
+		for i := len(in.stack) - 1; i > 0; i-- {
+			if in.stack[i] == step {
+				in.stack = in.stack[:i]
+				break
+			}
+		}
+	}
+}
+
+// Record add a finished step under the current step
+func (in *Trace) Record(name string, begin time.Time, duration time.Duration) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	parent := in.stack[len(in.stack)-1]
+	parent.Steps = append(parent.Steps, &TraceStep{Name: name, Begin: begin, Duration: duration})
+}
+
+// Duration the duration since the trace begins
+func (in *Trace) Duration() time.Duration {
+	return time.Since(in.root.Begin)
+}
+
+// Dump emits the trace as structured logs if the total duration exceeds
+// ReconcileTraceThreshold. Each step is logged with its path and duration.
+func (in *Trace) Dump(logger logr.Logger) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.root.Duration = time.Since(in.root.Begin)
+	if in.root.Duration < ReconcileTraceThreshold {
+		return
+	}
+	var dump func(path []string, step *TraceStep)
+	dump = func(path []string, step *TraceStep) {
+		path = append(path, step.Name)
+		logger.Info("reconcile trace", "step", strings.Join(path, "/"), "duration", step.Duration.String())
+		for _, child := range step.Steps {
+			dump(path, child)
+		}
+	}
+	dump(nil, in.root)
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubevela/pkg/util/runtime"
+)
+
+func TestReconcileTrace(t *testing.T) {
+	defer func(threshold time.Duration) { runtime.ReconcileTraceThreshold = threshold }(runtime.ReconcileTraceThreshold)
+	r := require.New(t)
+	ctx, trace := runtime.NewReconcileTrace(context.Background())
+	r.Equal(trace, runtime.TraceFrom(ctx))
+	r.Nil(runtime.TraceFrom(context.Background()))
+
+	closeFetch := trace.Step("fetch")
+	trace.Record("Get ConfigMap", time.Now(), time.Millisecond)
+	closeFetch()
+	closeApply := trace.Step("apply")
+	closeRender := trace.Step("render")
+	time.Sleep(10 * time.Millisecond)
+	closeRender()
+	closeApply()
+
+	var lines []string
+	logger := funcr.New(func(prefix, args string) { lines = append(lines, args) }, funcr.Options{})
+	runtime.ReconcileTraceThreshold = time.Hour
+	trace.Dump(logger)
+	r.Empty(lines)
+
+	runtime.ReconcileTraceThreshold = 0
+	trace.Dump(logger)
+	r.Equal(5, len(lines))
+	for i, step := range []string{"reconcile", "reconcile/fetch", "reconcile/fetch/Get ConfigMap", "reconcile/apply", "reconcile/apply/render"} {
+		r.Contains(lines[i], `"msg"="reconcile trace"`)
+		r.Contains(lines[i], `"step"="`+step+`"`)
+	}
+	r.Contains(lines[2], `"duration"="1ms"`)
+}
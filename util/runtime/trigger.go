@@ -0,0 +1,119 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	// ReconcileTriggerEvent the trigger label for reconciles triggered by
+	// object changes, requeues or unknown sources
+	ReconcileTriggerEvent = "event"
+	// ReconcileTriggerResync the trigger label for reconciles triggered only
+	// by informer resyncs
+	ReconcileTriggerResync = "resync"
+)
+
+// maxTrackedTriggers the max number of objects with pending reconciles tracked
+// by one controller, to bound the memory for objects never reconciled
+const maxTrackedTriggers = 10000
+
+// triggerTracker remembers whether the pending reconcile of each object is
+// triggered only by resyncs
+type triggerTracker struct {
+	mu         sync.Mutex
+	resyncOnly map[types.NamespacedName]bool
+}
+
+var controllerTriggerTrackers sync.Map
+
+func getTriggerTracker(controller string) *triggerTracker {
+	if tracker, found := controllerTriggerTrackers.Load(controller); found {
+		return tracker.(*triggerTracker)
+	}
+	tracker, _ := controllerTriggerTrackers.LoadOrStore(controller, &triggerTracker{resyncOnly: map[types.NamespacedName]bool{}})
+	return tracker.(*triggerTracker)
+}
+
+func (in *triggerTracker) mark(obj client.Object, resync bool) {
+	if obj == nil {
+		return
+	}
+	key := client.ObjectKeyFromObject(obj)
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	resyncOnly, found := in.resyncOnly[key]
+	if found && !resyncOnly {
+		return
+	}
+	if !found && len(in.resyncOnly) >= maxTrackedTriggers {
+		in.resyncOnly = map[types.NamespacedName]bool{}
+	}
+	in.resyncOnly[key] = resync
+}
+
+// take returns the trigger of the reconcile and clears the mark
+func (in *triggerTracker) take(key types.NamespacedName) string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	resyncOnly := in.resyncOnly[key]
+	delete(in.resyncOnly, key)
+	if resyncOnly {
+		return ReconcileTriggerResync
+	}
+	return ReconcileTriggerEvent
+}
+
+// ResyncPredicate tags the events of the controller as resync-triggered or
+// event-triggered, which is used by MonitorReconcile to set the trigger label.
+// Updates without resourceVersion change are resyncs. It never filters events.
+// The tag only applies to the reconciles of the objects' own keys, as events
+// mapped to other objects cannot be tracked.
+// It must be the last predicate of the watch, since the events are tagged
+// when the predicate is called, and the tag of an event dropped by a later
+// predicate would be taken by the next reconcile of the same key. As the
+// predicates set by WithEventFilter are called before the ones set by For,
+// Owns and Watches, use WithEventFilter only if the watches have no predicate.
+func ResyncPredicate(controller string) predicate.Predicate {
+	tracker := getTriggerTracker(controller)
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			tracker.mark(e.Object, false)
+			return true
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			resync := e.ObjectOld != nil && e.ObjectNew != nil &&
+				e.ObjectOld.GetResourceVersion() == e.ObjectNew.GetResourceVersion()
+			tracker.mark(e.ObjectNew, resync)
+			return true
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			tracker.mark(e.Object, false)
+			return true
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			tracker.mark(e.Object, false)
+			return true
+		},
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestResyncPredicate(t *testing.T) {
+	r := require.New(t)
+	controller := uniqueController("trigger")
+	p := ResyncPredicate(controller)
+	reconciler := MonitorReconcile(controller, reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		return reconcile.Result{}, nil
+	}))
+	a := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a", ResourceVersion: "1"}}
+	b1 := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "b", ResourceVersion: "1"}}
+	b2 := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "b", ResourceVersion: "2"}}
+	count := func(trigger string) uint64 {
+		m := &dto.Metric{}
+		r.NoError(controllerReconcileLatency.WithLabelValues(controller, ReconcileResultSuccess, ReconcilePhaseCold, trigger).(prometheus.Histogram).Write(m))
+		return m.Histogram.GetSampleCount()
+	}
+	doReconcile := func(obj client.Object) {
+		_, err := reconciler.Reconcile(context.Background(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+		r.NoError(err)
+	}
+
+	// resync only
+	r.True(p.Update(event.UpdateEvent{ObjectOld: a, ObjectNew: a}))
+	doReconcile(a)
+	r.Equal(uint64(1), count(ReconcileTriggerResync))
+	r.Equal(uint64(0), count(ReconcileTriggerEvent))
+
+	// resync merged with a real change
+	r.True(p.Update(event.UpdateEvent{ObjectOld: b1, ObjectNew: b2}))
+	r.True(p.Update(event.UpdateEvent{ObjectOld: b2, ObjectNew: b2}))
+	doReconcile(b2)
+	r.Equal(uint64(1), count(ReconcileTriggerEvent))
+
+	// untracked reconciles such as requeues
+	doReconcile(a)
+	r.Equal(uint64(2), count(ReconcileTriggerEvent))
+	r.Equal(uint64(1), count(ReconcileTriggerResync))
+}
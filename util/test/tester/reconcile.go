@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tester
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// TestingT the subset of testing.T used by the reconcile assertions
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// AssertRequeue asserts the reconcile succeeded and requested a requeue,
+// either immediately or after a delay
+func AssertRequeue(t TestingT, res reconcile.Result, err error) bool {
+	t.Helper()
+	if err != nil {
+		t.Errorf("expected reconcile to requeue, but it failed: %v", err)
+		return false
+	}
+	if !res.Requeue && res.RequeueAfter <= 0 {
+		t.Errorf("expected reconcile to requeue, but it is done: %+v", res)
+		return false
+	}
+	return true
+}
+
+// AssertRequeueAfter asserts the reconcile succeeded and requested a requeue
+// after exactly the given delay
+func AssertRequeueAfter(t TestingT, res reconcile.Result, err error, d time.Duration) bool {
+	t.Helper()
+	if err != nil {
+		t.Errorf("expected reconcile to requeue after %s, but it failed: %v", d, err)
+		return false
+	}
+	if res.RequeueAfter != d {
+		t.Errorf("expected reconcile to requeue after %s, but got %+v", d, res)
+		return false
+	}
+	return true
+}
+
+// AssertDone asserts the reconcile succeeded without requesting a requeue
+func AssertDone(t TestingT, res reconcile.Result, err error) bool {
+	t.Helper()
+	if err != nil {
+		t.Errorf("expected reconcile to be done, but it failed: %v", err)
+		return false
+	}
+	if !res.IsZero() {
+		t.Errorf("expected reconcile to be done, but it requested requeue: %+v", res)
+		return false
+	}
+	return true
+}
+
+// AssertIdempotent asserts the reconciler is idempotent. It reconciles the
+// request twice and asserts the second reconcile makes no writes through the
+// recorder, which wraps the client used by the reconciler. There should be no
+// external changes between the two reconciles.
+func AssertIdempotent(t TestingT, reconciler reconcile.Reconciler, req reconcile.Request, recorder *ReconcileRecorder) bool {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Errorf("expected reconcile to be idempotent, but the first reconcile of %s failed: %v", req, err)
+		return false
+	}
+	recorder.Reset()
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Errorf("expected reconcile to be idempotent, but the second reconcile of %s failed: %v", req, err)
+		return false
+	}
+	if writes := recorder.Writes(); len(writes) > 0 {
+		t.Errorf("expected reconcile to be idempotent, but the second reconcile of %s made writes: %v", req, writes)
+		return false
+	}
+	return true
+}
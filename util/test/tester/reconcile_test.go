@@ -0,0 +1,165 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tester_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kubevela/pkg/util/test/tester"
+)
+
+// recordingT records the failures instead of failing the test
+type recordingT struct {
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestReconcileAssertions(t *testing.T) {
+	failed := fmt.Errorf("boom")
+	testcases := map[string]struct {
+		assert func(tester.TestingT) bool
+		errMsg string
+	}{
+		"requeue": {
+			assert: func(t tester.TestingT) bool { return tester.AssertRequeue(t, reconcile.Result{Requeue: true}, nil) },
+		},
+		"requeue-with-delay": {
+			assert: func(t tester.TestingT) bool {
+				return tester.AssertRequeue(t, reconcile.Result{RequeueAfter: time.Second}, nil)
+			},
+		},
+		"requeue-but-done": {
+			assert: func(t tester.TestingT) bool { return tester.AssertRequeue(t, reconcile.Result{}, nil) },
+			errMsg: "expected reconcile to requeue, but it is done",
+		},
+		"requeue-but-failed": {
+			assert: func(t tester.TestingT) bool { return tester.AssertRequeue(t, reconcile.Result{Requeue: true}, failed) },
+			errMsg: "expected reconcile to requeue, but it failed: boom",
+		},
+		"requeue-after": {
+			assert: func(t tester.TestingT) bool {
+				return tester.AssertRequeueAfter(t, reconcile.Result{RequeueAfter: time.Minute}, nil, time.Minute)
+			},
+		},
+		"requeue-after-mismatch": {
+			assert: func(t tester.TestingT) bool {
+				return tester.AssertRequeueAfter(t, reconcile.Result{RequeueAfter: time.Second}, nil, time.Minute)
+			},
+			errMsg: "expected reconcile to requeue after 1m0s, but got",
+		},
+		"requeue-after-but-failed": {
+			assert: func(t tester.TestingT) bool {
+				return tester.AssertRequeueAfter(t, reconcile.Result{}, failed, time.Minute)
+			},
+			errMsg: "expected reconcile to requeue after 1m0s, but it failed: boom",
+		},
+		"done": {
+			assert: func(t tester.TestingT) bool { return tester.AssertDone(t, reconcile.Result{}, nil) },
+		},
+		"done-but-requeue": {
+			assert: func(t tester.TestingT) bool { return tester.AssertDone(t, reconcile.Result{Requeue: true}, nil) },
+			errMsg: "expected reconcile to be done, but it requested requeue",
+		},
+		"done-but-failed": {
+			assert: func(t tester.TestingT) bool { return tester.AssertDone(t, reconcile.Result{}, failed) },
+			errMsg: "expected reconcile to be done, but it failed: boom",
+		},
+	}
+	for name, tt := range testcases {
+		t.Run(name, func(t *testing.T) {
+			rt := &recordingT{}
+			ok := tt.assert(rt)
+			if tt.errMsg == "" {
+				require.True(t, ok)
+				require.Empty(t, rt.errors)
+				return
+			}
+			require.False(t, ok)
+			require.Len(t, rt.errors, 1)
+			require.Contains(t, rt.errors[0], tt.errMsg)
+		})
+	}
+}
+
+// idempotentSecretReconciler ensures a ConfigMap exists for each Secret, and
+// only updates it when the data differs
+type idempotentSecretReconciler struct {
+	client.Client
+}
+
+func (r *idempotentSecretReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, req.NamespacedName, secret); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	cm := &corev1.ConfigMap{}
+	err := r.Get(ctx, req.NamespacedName, cm)
+	if kerrors.IsNotFound(err) {
+		cm.ObjectMeta = metav1.ObjectMeta{Namespace: req.Namespace, Name: req.Name}
+		cm.Data = secret.StringData
+		return reconcile.Result{}, r.Create(ctx, cm)
+	}
+	if err != nil || reflect.DeepEqual(cm.Data, secret.StringData) {
+		return reconcile.Result{}, err
+	}
+	cm.Data = secret.StringData
+	return reconcile.Result{}, r.Update(ctx, cm)
+}
+
+func TestAssertIdempotent(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example"},
+		StringData: map[string]string{"key": "value"},
+	}
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(secret)}
+
+	recorder := tester.NewReconcileRecorder(fake.NewClientBuilder().WithObjects(secret).Build())
+	rt := &recordingT{}
+	require.True(t, tester.AssertIdempotent(rt, &idempotentSecretReconciler{Client: recorder}, req, recorder))
+	require.Empty(t, rt.errors)
+
+	recorder = tester.NewReconcileRecorder(fake.NewClientBuilder().WithObjects(secret).Build())
+	rt = &recordingT{}
+	require.False(t, tester.AssertIdempotent(rt, &secretReconciler{Client: recorder}, req, recorder))
+	require.Len(t, rt.errors, 1)
+	require.Contains(t, rt.errors[0], "made writes: [Update ConfigMap default/example]")
+
+	recorder = tester.NewReconcileRecorder(fake.NewClientBuilder().Build())
+	rt = &recordingT{}
+	failing := reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+		return reconcile.Result{}, fmt.Errorf("boom")
+	})
+	require.False(t, tester.AssertIdempotent(rt, failing, req, recorder))
+	require.Contains(t, rt.errors[0], "the first reconcile of default/example failed: boom")
+}
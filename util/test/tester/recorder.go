@@ -0,0 +1,188 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tester
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kubevela/pkg/util/k8s"
+)
+
+// Operation a client operation recorded by ReconcileRecorder
+type Operation struct {
+	Verb string
+	Kind string
+	Key  client.ObjectKey
+}
+
+// String .
+func (op Operation) String() string {
+	if op.Key.Name == "" && op.Key.Namespace == "" {
+		return fmt.Sprintf("%s %s", op.Verb, op.Kind)
+	}
+	return fmt.Sprintf("%s %s %s", op.Verb, op.Kind, op.Key)
+}
+
+// IsWrite check if the operation modifies objects
+func (op Operation) IsWrite() bool {
+	switch op.Verb {
+	case "Get", "List":
+		return false
+	default:
+		return true
+	}
+}
+
+// ReconcileRecorder wraps the client and records all the operations in order,
+// so that tests can assert the exact sequence of calls made by reconciler.
+type ReconcileRecorder struct {
+	client.Client
+
+	mu         sync.Mutex
+	operations []Operation
+}
+
+var _ client.Client = &ReconcileRecorder{}
+
+// NewReconcileRecorder create a ReconcileRecorder for the client, which is
+// usually a fake client
+func NewReconcileRecorder(c client.Client) *ReconcileRecorder {
+	return &ReconcileRecorder{Client: c}
+}
+
+func (r *ReconcileRecorder) record(verb string, obj runtime.Object, key client.ObjectKey) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.operations = append(r.operations, Operation{
+		Verb: verb,
+		Kind: k8s.GetKindForObject(obj, true),
+		Key:  key,
+	})
+}
+
+// Operations return the recorded operations in order
+func (r *ReconcileRecorder) Operations() []Operation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Operation{}, r.operations...)
+}
+
+// Writes return the recorded write operations in order
+func (r *ReconcileRecorder) Writes() []Operation {
+	var writes []Operation
+	for _, op := range r.Operations() {
+		if op.IsWrite() {
+			writes = append(writes, op)
+		}
+	}
+	return writes
+}
+
+// Reset clear the recorded operations
+func (r *ReconcileRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.operations = nil
+}
+
+// Replay reconciles the objects in order with the reconciler. It stops when
+// the context is cancelled or any reconcile fails.
+func (r *ReconcileRecorder) Replay(ctx context.Context, reconciler reconcile.Reconciler, objs ...client.Object) ([]reconcile.Result, error) {
+	var results []reconcile.Result
+	for _, obj := range objs {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		res, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+		if err != nil {
+			return results, err
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// Get .
+func (r *ReconcileRecorder) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	r.record("Get", obj, key)
+	return r.Client.Get(ctx, key, obj)
+}
+
+// List .
+func (r *ReconcileRecorder) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	r.record("List", list, client.ObjectKey{})
+	return r.Client.List(ctx, list, opts...)
+}
+
+// Create .
+func (r *ReconcileRecorder) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	r.record("Create", obj, client.ObjectKeyFromObject(obj))
+	return r.Client.Create(ctx, obj, opts...)
+}
+
+// Delete .
+func (r *ReconcileRecorder) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	r.record("Delete", obj, client.ObjectKeyFromObject(obj))
+	return r.Client.Delete(ctx, obj, opts...)
+}
+
+// Update .
+func (r *ReconcileRecorder) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	r.record("Update", obj, client.ObjectKeyFromObject(obj))
+	return r.Client.Update(ctx, obj, opts...)
+}
+
+// Patch .
+func (r *ReconcileRecorder) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	r.record("Patch", obj, client.ObjectKeyFromObject(obj))
+	return r.Client.Patch(ctx, obj, patch, opts...)
+}
+
+// DeleteAllOf .
+func (r *ReconcileRecorder) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	r.record("DeleteAllOf", obj, client.ObjectKeyFromObject(obj))
+	return r.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+// Status .
+func (r *ReconcileRecorder) Status() client.StatusWriter {
+	return &recorderStatusWriter{StatusWriter: r.Client.Status(), recorder: r}
+}
+
+// recorderStatusWriter records status operations into ReconcileRecorder
+type recorderStatusWriter struct {
+	client.StatusWriter
+	recorder *ReconcileRecorder
+}
+
+// Update .
+func (w *recorderStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	w.recorder.record("StatusUpdate", obj, client.ObjectKeyFromObject(obj))
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+// Patch .
+func (w *recorderStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	w.recorder.record("StatusPatch", obj, client.ObjectKeyFromObject(obj))
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tester_test
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kubevela/pkg/util/test/tester"
+)
+
+// secretReconciler ensures a ConfigMap exists for each Secret
+type secretReconciler struct {
+	client.Client
+}
+
+func (r *secretReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, req.NamespacedName, secret); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	cm := &corev1.ConfigMap{}
+	err := r.Get(ctx, req.NamespacedName, cm)
+	if kerrors.IsNotFound(err) {
+		cm.ObjectMeta = metav1.ObjectMeta{Namespace: req.Namespace, Name: req.Name}
+		return reconcile.Result{}, r.Create(ctx, cm)
+	}
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	cm.Data = secret.StringData
+	return reconcile.Result{}, r.Update(ctx, cm)
+}
+
+func ExampleReconcileRecorder() {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example"}}
+	recorder := tester.NewReconcileRecorder(fake.NewClientBuilder().WithObjects(secret).Build())
+	reconciler := &secretReconciler{Client: recorder}
+	if _, err := recorder.Replay(context.Background(), reconciler, secret, secret); err != nil {
+		fmt.Println(err)
+	}
+	for _, op := range recorder.Operations() {
+		fmt.Println(op)
+	}
+	fmt.Println(len(recorder.Writes()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	recorder.Reset()
+	_, err := recorder.Replay(ctx, reconciler, secret)
+	fmt.Println(err, len(recorder.Operations()))
+	// Output:
+	// Get Secret default/example
+	// Get ConfigMap default/example
+	// Create ConfigMap default/example
+	// Get Secret default/example
+	// Get ConfigMap default/example
+	// Update ConfigMap default/example
+	// 2
+	// context canceled 0
+}